@@ -0,0 +1,83 @@
+package jago
+
+import (
+	"crypto/subtle"
+	"strconv"
+)
+
+type (
+	BasicAuthConfig struct {
+		Skipper Skipper
+
+		// Validator checks the credentials. It is required.
+		Validator BasicAuthValidator
+
+		// Realm is sent in the WWW-Authenticate header. Defaults to
+		// "Restricted".
+		Realm string
+	}
+
+	// BasicAuthValidator reports whether user and password are valid. A
+	// non-nil error is passed to HTTPErrorHandler as is.
+	BasicAuthValidator func(user, password string, c Context) (bool, error)
+)
+
+// AuthUserKey is the Context key holding the user authenticated by
+// BasicAuth.
+const AuthUserKey = "user"
+
+var DefaultBasicAuthConfig = BasicAuthConfig{
+	Skipper: DefaultSkipper,
+	Realm:   "Restricted",
+}
+
+// BasicAuth authenticates requests with HTTP basic authentication. Failed
+// attempts get 401 with a WWW-Authenticate challenge.
+func BasicAuth(validator BasicAuthValidator) HandlerFunc {
+	config := DefaultBasicAuthConfig
+	config.Validator = validator
+	return BasicAuthWithConfig(config)
+}
+
+func BasicAuthWithConfig(config BasicAuthConfig) HandlerFunc {
+	if config.Validator == nil {
+		panic("jago: basic-auth middleware requires a validator")
+	}
+	if config.Skipper == nil {
+		config.Skipper = DefaultBasicAuthConfig.Skipper
+	}
+	if config.Realm == "" {
+		config.Realm = DefaultBasicAuthConfig.Realm
+	}
+	challenge := "Basic realm=" + strconv.Quote(config.Realm)
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+
+		if user, password, ok := c.Request().BasicAuth(); ok {
+			valid, err := config.Validator(user, password, c)
+			if err != nil {
+				return err
+			}
+			if valid {
+				c.Set(AuthUserKey, user)
+				return c.Next()
+			}
+		}
+
+		c.Response().Header().Set(HeaderWWWAuthenticate, challenge)
+		return ErrUnauthorized
+	}
+}
+
+// BasicAuthAccounts validates against a fixed user to password map. The
+// password is compared in constant time.
+func BasicAuthAccounts(accounts map[string]string) BasicAuthValidator {
+	return func(user, password string, c Context) (bool, error) {
+		expected, ok := accounts[user]
+		match := subtle.ConstantTimeCompare([]byte(password), []byte(expected)) == 1
+		return ok && match, nil
+	}
+}
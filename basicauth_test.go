@@ -0,0 +1,56 @@
+package jago
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicAuth(t *testing.T) {
+	g := New()
+	g.Use(BasicAuth(BasicAuthAccounts(map[string]string{"jago": "secret"})))
+	g.Get("/me", func(c Context) error {
+		return c.String(http.StatusOK, c.GetString(AuthUserKey))
+	})
+
+	auth := func(user, password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		if user != "" {
+			req.SetBasicAuth(user, password)
+		}
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := auth("jago", "secret")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "jago", rec.Body.String())
+
+	for _, rec := range []*httptest.ResponseRecorder{auth("", ""), auth("jago", "wrong"), auth("nobody", "")} {
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, `Basic realm="Restricted"`, rec.Header().Get(HeaderWWWAuthenticate))
+	}
+}
+
+func TestBasicAuthValidatorError(t *testing.T) {
+	g := New()
+	g.Use(BasicAuthWithConfig(BasicAuthConfig{
+		Realm: "Admin",
+		Validator: func(user, password string, c Context) (bool, error) {
+			return false, errors.New("directory unavailable")
+		},
+	}))
+	g.Get("/me", jagoHandler(http.MethodGet, "/me"))
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.SetBasicAuth("jago", "secret")
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	assert.Panics(t, func() { BasicAuth(nil) })
+}
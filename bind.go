@@ -0,0 +1,334 @@
+package jago
+
+import (
+	"encoding"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// FieldError describes why a single struct field could not be bound or
+	// validated.
+	FieldError struct {
+		Field   string `json:"field"`
+		Source  string `json:"source,omitempty"`
+		Message string `json:"message"`
+	}
+
+	// FieldErrors is used as the HTTPError message when one or more fields
+	// fail to bind or validate.
+	FieldErrors []*FieldError
+
+	valueLookup func(name string) ([]string, bool)
+)
+
+// Tag names used by Bind to map request data onto struct fields.
+const (
+	bindTagParam  = "param"
+	bindTagQuery  = "query"
+	bindTagHeader = "header"
+	bindTagForm   = "form"
+	bindTagFormat = "format"
+
+	defaultMultipartMemory = 32 << 20
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+)
+
+func (fe FieldErrors) Error() string {
+	msgs := make([]string, len(fe))
+	for i, e := range fe {
+		msgs[i] = e.Field + ": " + e.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Bind fills i from the path params, query string and headers according to
+// its `param`, `query` and `header` tags, then decodes the request body
+// based on its Content-Type. Form bodies are mapped with the `form` tag.
+// The result is validated when Jago.AutoValidate is set.
+func (c *context) Bind(i interface{}) error {
+	if errs := c.bindData(i); len(errs) > 0 {
+		return NewHTTPError(http.StatusBadRequest, errs)
+	}
+	if err := c.bindBody(i); err != nil {
+		return err
+	}
+	if c.j.AutoValidate && c.j.Validator != nil {
+		return c.Validate(i)
+	}
+	return nil
+}
+
+func (c *context) bindData(i interface{}) (errs FieldErrors) {
+	v := reflect.ValueOf(i)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	v = v.Elem()
+
+	errs = append(errs, bindValues(v, bindTagParam, func(name string) ([]string, bool) {
+		for i, n := range c.pnames {
+			if n == name {
+				return c.pvalues[i : i+1], true
+			}
+		}
+		return nil, false
+	})...)
+	errs = append(errs, bindValues(v, bindTagQuery, func(name string) ([]string, bool) {
+		vals, ok := c.QueryParams()[name]
+		return vals, ok
+	})...)
+	errs = append(errs, bindValues(v, bindTagHeader, func(name string) ([]string, bool) {
+		vals := c.request.Header.Values(name)
+		return vals, len(vals) > 0
+	})...)
+	return
+}
+
+func (c *context) bindBody(i interface{}) error {
+	req := c.request
+	if req.ContentLength == 0 || req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	ctype := req.Header.Get(HeaderContentType)
+	switch {
+	case strings.HasPrefix(ctype, MIMEApplicationJSON):
+		if err := c.BindJson(i); err != nil && err != io.EOF {
+			return badRequest(err)
+		}
+	case strings.HasPrefix(ctype, MIMEApplicationXML), strings.HasPrefix(ctype, MIMETextXML):
+		if err := xml.NewDecoder(req.Body).Decode(i); err != nil && err != io.EOF {
+			if ute, ok := err.(*xml.UnsupportedTypeError); ok {
+				return NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unsupported type error: type=%v, error=%v", ute.Type, ute.Error()))
+			} else if se, ok := err.(*xml.SyntaxError); ok {
+				return NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Syntax error: line=%v, error=%v", se.Line, se.Error()))
+			}
+			return badRequest(err)
+		}
+	case strings.HasPrefix(ctype, MIMEApplicationForm), strings.HasPrefix(ctype, MIMEMultipartForm):
+		return c.bindForm(i)
+	default:
+		return ErrUnsupportedMediaType
+	}
+	return nil
+}
+
+func (c *context) bindForm(i interface{}) error {
+	req := c.request
+	var (
+		values url.Values
+		files  map[string][]*multipart.FileHeader
+	)
+	if strings.HasPrefix(req.Header.Get(HeaderContentType), MIMEMultipartForm) {
+		if err := req.ParseMultipartForm(defaultMultipartMemory); err != nil {
+			return badRequest(err)
+		}
+		values = req.MultipartForm.Value
+		files = req.MultipartForm.File
+	} else {
+		if err := req.ParseForm(); err != nil {
+			return badRequest(err)
+		}
+		values = req.PostForm
+	}
+
+	v := reflect.ValueOf(i)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return NewHTTPError(http.StatusBadRequest, "form data can only be bound to a struct")
+	}
+	bindFiles(v.Elem(), files)
+	errs := bindValues(v.Elem(), bindTagForm, func(name string) ([]string, bool) {
+		vals, ok := values[name]
+		return vals, ok
+	})
+	if len(errs) > 0 {
+		return NewHTTPError(http.StatusBadRequest, errs)
+	}
+	return nil
+}
+
+// badRequest reports a body that cannot be decoded, keeping HTTPErrors
+// raised while reading it, such as the one from BodyLimit.
+func badRequest(err error) error {
+	var he *HTTPError
+	if errors.As(err, &he) {
+		return he
+	}
+	return NewHTTPError(http.StatusBadRequest, err.Error())
+}
+
+// bindValues sets every field of v tagged with tag from the values returned
+// by lookup, descending into untagged struct fields.
+func bindValues(v reflect.Value, tag string, lookup valueLookup) (errs FieldErrors) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		name := sf.Tag.Get(tag)
+		if name == "" {
+			if sf.Type.Kind() == reflect.Struct && sf.Type != timeType {
+				errs = append(errs, bindValues(field, tag, lookup)...)
+			}
+			continue
+		}
+		if name == "-" {
+			continue
+		}
+		if sf.Type == fileHeaderType || (sf.Type.Kind() == reflect.Slice && sf.Type.Elem() == fileHeaderType) {
+			continue
+		}
+		vals, ok := lookup(name)
+		if !ok || len(vals) == 0 {
+			continue
+		}
+		if err := setField(field, vals, sf.Tag.Get(bindTagFormat)); err != nil {
+			errs = append(errs, &FieldError{Field: name, Source: tag, Message: err.Error()})
+		}
+	}
+	return
+}
+
+func bindFiles(v reflect.Value, files map[string][]*multipart.FileHeader) {
+	if len(files) == 0 {
+		return
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		field := v.Field(i)
+		name := sf.Tag.Get(bindTagForm)
+		if name == "" || !field.CanSet() {
+			continue
+		}
+		fhs := files[name]
+		if len(fhs) == 0 {
+			continue
+		}
+		switch {
+		case sf.Type == fileHeaderType:
+			field.Set(reflect.ValueOf(fhs[0]))
+		case sf.Type.Kind() == reflect.Slice && sf.Type.Elem() == fileHeaderType:
+			field.Set(reflect.ValueOf(fhs))
+		}
+	}
+}
+
+func setField(field reflect.Value, vals []string, format string) error {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return setField(field.Elem(), vals, format)
+	}
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(field.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := setValue(slice.Index(i), val, format); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setValue(field, vals[0], format)
+}
+
+func setValue(field reflect.Value, val, format string) error {
+	switch field.Type() {
+	case timeType:
+		if val == "" {
+			return nil
+		}
+		if format == "" {
+			format = time.RFC3339
+		}
+		t, err := time.Parse(format, val)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if val == "" {
+			val = "0"
+		}
+		i, err := strconv.ParseInt(val, 10, field.Type().Bits())
+		if err != nil {
+			return numError(err)
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if val == "" {
+			val = "0"
+		}
+		u, err := strconv.ParseUint(val, 10, field.Type().Bits())
+		if err != nil {
+			return numError(err)
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		if val == "" {
+			val = "0"
+		}
+		f, err := strconv.ParseFloat(val, field.Type().Bits())
+		if err != nil {
+			return numError(err)
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		if val == "" {
+			val = "false"
+		}
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return numError(err)
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// numError strips the strconv function name from conversion errors.
+func numError(err error) error {
+	var ne *strconv.NumError
+	if errors.As(err, &ne) {
+		return fmt.Errorf("invalid value %q: %v", ne.Num, ne.Err)
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package jago
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bindUser struct {
+	ID      int           `param:"id"`
+	Page    uint          `query:"page"`
+	Tags    []string      `query:"tag"`
+	Active  *bool         `query:"active"`
+	Since   time.Time     `query:"since" format:"2006-01-02"`
+	Token   string        `header:"X-Token"`
+	Name    string        `json:"name" xml:"name" form:"name"`
+	Score   float64       `json:"score" xml:"score" form:"score"`
+	Timeout time.Duration `query:"timeout"`
+}
+
+func bindContext(g *Jago, method, path, target, contentType, body string) Context {
+	g.Add(method, path, NotFoundHandler)
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set(HeaderContentType, contentType)
+	}
+	req.Header.Set("X-Token", "secret")
+	c := g.NewContext(req, httptest.NewRecorder())
+	g.router.find(req.URL.Path, method, c)
+	return c
+}
+
+func TestBindPathQueryHeaderJSON(t *testing.T) {
+	g := New()
+	c := bindContext(g, http.MethodPost, "/users/:id", "/users/7?page=2&tag=a&tag=b&active=true&since=2022-03-04&timeout=1m",
+		MIMEApplicationJSON, `{"name":"jago","score":1.5}`)
+
+	u := new(bindUser)
+	assert.NoError(t, c.Bind(u))
+	assert.Equal(t, 7, u.ID)
+	assert.Equal(t, uint(2), u.Page)
+	assert.Equal(t, []string{"a", "b"}, u.Tags)
+	assert.True(t, *u.Active)
+	assert.Equal(t, time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC), u.Since)
+	assert.Equal(t, time.Minute, u.Timeout)
+	assert.Equal(t, "secret", u.Token)
+	assert.Equal(t, "jago", u.Name)
+	assert.Equal(t, 1.5, u.Score)
+}
+
+func TestBindXMLAndForm(t *testing.T) {
+	g := New()
+	c := bindContext(g, http.MethodPut, "/users/:id", "/users/1", MIMEApplicationXML, `<user><name>jago</name><score>2</score></user>`)
+	u := new(bindUser)
+	assert.NoError(t, c.Bind(u))
+	assert.Equal(t, "jago", u.Name)
+	assert.Equal(t, 2.0, u.Score)
+
+	g = New()
+	c = bindContext(g, http.MethodPut, "/users/:id", "/users/1", MIMEApplicationForm, "name=form&score=3.5")
+	u = new(bindUser)
+	assert.NoError(t, c.Bind(u))
+	assert.Equal(t, "form", u.Name)
+	assert.Equal(t, 3.5, u.Score)
+}
+
+func TestBindMultipart(t *testing.T) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	mw.WriteField("name", "multi")
+	fw, _ := mw.CreateFormFile("avatar", "a.png")
+	fw.Write([]byte("png"))
+	mw.Close()
+
+	g := New()
+	c := bindContext(g, http.MethodPost, "/upload", "/upload", mw.FormDataContentType(), body.String())
+	var form struct {
+		Name   string                `form:"name"`
+		Avatar *multipart.FileHeader `form:"avatar"`
+	}
+	assert.NoError(t, c.Bind(&form))
+	assert.Equal(t, "multi", form.Name)
+	assert.Equal(t, "a.png", form.Avatar.Filename)
+}
+
+func TestBindErrors(t *testing.T) {
+	g := New()
+	c := bindContext(g, http.MethodGet, "/users/:id", "/users/abc?page=-1", "", "")
+	err := c.Bind(new(bindUser))
+	he, ok := err.(*HTTPError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, he.Code)
+	errs := he.Message.(FieldErrors)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "id", errs[0].Field)
+	assert.Equal(t, "param", errs[0].Source)
+	assert.Equal(t, "page", errs[1].Field)
+
+	g = New()
+	c = bindContext(g, http.MethodPost, "/users", "/users", MIMETextPlain, "hello")
+	assert.Equal(t, ErrUnsupportedMediaType, c.Bind(new(bindUser)))
+
+	g = New()
+	c = bindContext(g, http.MethodPost, "/users", "/users", MIMEApplicationJSON, "{")
+	he, ok = c.Bind(new(bindUser)).(*HTTPError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, he.Code)
+}
@@ -0,0 +1,102 @@
+package jago
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type (
+	BodyLimitConfig struct {
+		Skipper Skipper
+
+		// Limit is the maximum request body size, such as "512K", "2M" or
+		// "1G". Units are powers of 1024.
+		Limit string
+	}
+
+	limitedReader struct {
+		io.ReadCloser
+		limit int64
+		read  int64
+	}
+)
+
+// BodyLimit rejects request bodies larger than limit with 413. Bodies
+// without a Content-Length are counted while the handler reads them. Use it
+// with Jago.Use, Group.Use or as a route handler for finer control.
+func BodyLimit(limit string) HandlerFunc {
+	return BodyLimitWithConfig(BodyLimitConfig{Limit: limit})
+}
+
+func BodyLimitWithConfig(config BodyLimitConfig) HandlerFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultSkipper
+	}
+	limit, err := parseBytes(config.Limit)
+	if err != nil {
+		panic(fmt.Sprintf("jago: invalid body limit %q: %v", config.Limit, err))
+	}
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+
+		req := c.Request()
+		if req.ContentLength > limit {
+			return ErrStatusRequestEntityTooLarge
+		}
+		if req.Body != nil {
+			req.Body = &limitedReader{ReadCloser: req.Body, limit: limit}
+		}
+		return c.Next()
+	}
+}
+
+// Read fails once the body exceeds the limit. Bytes past the limit are
+// withheld so decoders cannot finish a value from a truncated body.
+func (r *limitedReader) Read(b []byte) (n int, err error) {
+	n, err = r.ReadCloser.Read(b)
+	r.read += int64(n)
+	if r.read > r.limit {
+		n -= int(r.read - r.limit)
+		if n < 0 {
+			n = 0
+		}
+		r.read = r.limit + 1
+		return n, ErrStatusRequestEntityTooLarge
+	}
+	return
+}
+
+// parseBytes parses a size such as "10", "512K", "2MB" or "1G".
+func parseBytes(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	s = strings.TrimSuffix(s, "B")
+	multiplier := int64(1)
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			s = s[:len(s)-1]
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("negative size")
+	}
+	return int64(n * float64(multiplier)), nil
+}
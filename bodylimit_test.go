@@ -0,0 +1,84 @@
+package jago
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBytes(t *testing.T) {
+	for s, want := range map[string]int64{"10": 10, "2K": 2048, "1.5kb": 1536, "2M": 2 << 20, "1GB": 1 << 30, "3B": 3} {
+		n, err := parseBytes(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, want, n, s)
+	}
+	_, err := parseBytes("lots")
+	assert.Error(t, err)
+}
+
+func TestBodyLimit(t *testing.T) {
+	g := New()
+	g.Post("/echo", BodyLimit("8B"), func(c Context) error {
+		var v struct {
+			Name string `json:"name"`
+		}
+		if err := c.Bind(&v); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, v.Name)
+	})
+	g.Post("/raw", BodyLimit("8B"), func(c Context) error {
+		b, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, string(b))
+	})
+
+	post := func(target, body string, chunked bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+		if chunked {
+			req.ContentLength = -1
+		}
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, post("/raw", "12345678", false).Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post("/raw", "123456789", false).Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post("/raw", "123456789", true).Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post("/echo", `{"name":"too long"}`, true).Code)
+	rec := post("/echo", `{"a":1}`, true)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestBodyLimitGroup(t *testing.T) {
+	g := New()
+	api := g.Group("/api", BodyLimit("4B"))
+	api.Post("/upload", func(c Context) error {
+		_, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+	g.Post("/open", func(c Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/upload", strings.NewReader("12345"))
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/open", strings.NewReader("12345"))
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
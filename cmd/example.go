@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/JamesYYang/jago"
 )
@@ -522,12 +527,22 @@ func main() {
 	// loadJagoRoutes(jago, githubAPI)
 	// loadJagoRoutes(jago, parseAPI)
 	loadJagoRoutes(jago, complexAPI)
-	server := &http.Server{
-		Handler: jago,
-		Addr:    ":8080",
-	}
 	jago.PrintRouter()
-	log.Fatal(server.ListenAndServe())
+
+	go func() {
+		if err := jago.Start(":8080"); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := jago.Shutdown(ctx); err != nil {
+		log.Fatal(err)
+	}
 }
 
 func loadJagoRoutes(g *jago.Jago, routes []*Route) {
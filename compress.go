@@ -0,0 +1,348 @@
+package jago
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type (
+	CompressConfig struct {
+		Skipper Skipper
+
+		// Level is the compression level passed to the encoders. Defaults
+		// to gzip.DefaultCompression.
+		Level int
+
+		// MinLength is the smallest body, in bytes, worth compressing.
+		// Smaller responses are sent as is unless flushed early.
+		MinLength int
+
+		// Encodings lists the supported content codings by server
+		// preference. Defaults to gzip then deflate.
+		Encodings []string
+
+		// Encoders adds or replaces encoders by content coding name, e.g. to
+		// plug in a brotli implementation as "br".
+		Encoders map[string]CompressEncoder
+
+		// ExcludedContentTypes lists media type prefixes that are already
+		// compressed and are sent as is.
+		ExcludedContentTypes []string
+	}
+
+	// Compressor is a streaming encoder that can be reused with Reset.
+	Compressor interface {
+		io.WriteCloser
+		Flush() error
+		Reset(w io.Writer)
+	}
+
+	CompressEncoder func(w io.Writer, level int) (Compressor, error)
+
+	compressWriter struct {
+		http.ResponseWriter
+		config      *CompressConfig
+		encoding    string
+		pool        *sync.Pool
+		encoder     Compressor
+		buf         []byte
+		code        int
+		wroteHeader bool
+		decided     bool
+	}
+)
+
+const (
+	gzipEncoding    = "gzip"
+	deflateEncoding = "deflate"
+)
+
+var DefaultCompressConfig = CompressConfig{
+	Skipper:   DefaultSkipper,
+	Level:     gzip.DefaultCompression,
+	MinLength: 1024,
+	Encodings: []string{gzipEncoding, deflateEncoding},
+	ExcludedContentTypes: []string{
+		"image/png", "image/jpeg", "image/gif", "image/webp", "image/avif",
+		"video/", "audio/", "font/woff",
+		"application/zip", "application/gzip", "application/x-gzip",
+		"application/x-bzip2", "application/x-7z-compressed", "application/x-rar-compressed",
+		"application/wasm",
+	},
+}
+
+var builtinEncoders = map[string]CompressEncoder{
+	gzipEncoding: func(w io.Writer, level int) (Compressor, error) {
+		return gzip.NewWriterLevel(w, level)
+	},
+	deflateEncoding: func(w io.Writer, level int) (Compressor, error) {
+		return flate.NewWriter(w, level)
+	},
+}
+
+// Compress compresses response bodies with the best encoding accepted by
+// the client.
+func Compress() HandlerFunc {
+	return CompressWithConfig(DefaultCompressConfig)
+}
+
+func CompressWithConfig(config CompressConfig) HandlerFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultCompressConfig.Skipper
+	}
+	if config.Level == 0 {
+		config.Level = DefaultCompressConfig.Level
+	}
+	if len(config.Encodings) == 0 {
+		config.Encodings = DefaultCompressConfig.Encodings
+	}
+	if config.ExcludedContentTypes == nil {
+		config.ExcludedContentTypes = DefaultCompressConfig.ExcludedContentTypes
+	}
+
+	pools := make(map[string]*sync.Pool, len(config.Encodings))
+	for _, name := range config.Encodings {
+		newEncoder, ok := config.Encoders[name]
+		if !ok {
+			if newEncoder, ok = builtinEncoders[name]; !ok {
+				panic("jago: no encoder for content coding " + name)
+			}
+		}
+		if _, err := newEncoder(io.Discard, config.Level); err != nil {
+			panic("jago: " + err.Error())
+		}
+		pools[name] = &sync.Pool{
+			New: func() interface{} {
+				enc, _ := newEncoder(io.Discard, config.Level)
+				return enc
+			},
+		}
+	}
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+
+		res := c.Response()
+		res.Header().Add(HeaderVary, HeaderAcceptEncoding)
+		req := c.Request()
+		encoding := negotiateEncoding(req.Header.Get(HeaderAcceptEncoding), config.Encodings)
+		if encoding == "" || req.Method == http.MethodHead {
+			return c.Next()
+		}
+
+		cw := &compressWriter{
+			ResponseWriter: res.Writer,
+			config:         &config,
+			encoding:       encoding,
+			pool:           pools[encoding],
+		}
+		res.Writer = cw
+		defer func() {
+			res.Writer = cw.ResponseWriter
+		}()
+
+		err := c.Next()
+		if closeErr := cw.close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.code = code
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.decided {
+		if w.encoder != nil {
+			return w.encoder.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buf = append(w.buf, b...)
+	if len(w.buf) < w.config.MinLength {
+		return len(b), nil
+	}
+	if err := w.decide(true); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Flush sends buffered data right away, compressing it whatever its size
+// so streamed responses stay compressed.
+func (w *compressWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		if err := w.decide(true); err != nil {
+			return
+		}
+	}
+	if w.encoder != nil {
+		w.encoder.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("jago: response writer does not support hijacking")
+}
+
+// decide sends the header and the buffered body, through an encoder when
+// want is true and the response qualifies for compression.
+func (w *compressWriter) decide(want bool) error {
+	w.decided = true
+	header := w.ResponseWriter.Header()
+	if header.Get(HeaderContentType) == "" && len(w.buf) > 0 {
+		header.Set(HeaderContentType, http.DetectContentType(w.buf))
+	}
+
+	if want && w.compressible() {
+		header.Set(HeaderContentEncoding, w.encoding)
+		header.Del(HeaderContentLength)
+		w.encoder = w.pool.Get().(Compressor)
+		w.encoder.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (w *compressWriter) compressible() bool {
+	if w.code < http.StatusOK || w.code == http.StatusNoContent || w.code == http.StatusNotModified {
+		return false
+	}
+	header := w.ResponseWriter.Header()
+	if header.Get(HeaderContentEncoding) != "" {
+		return false
+	}
+	ctype := strings.ToLower(header.Get(HeaderContentType))
+	for _, excluded := range w.config.ExcludedContentTypes {
+		if strings.HasPrefix(ctype, excluded) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *compressWriter) close() error {
+	if !w.decided {
+		if !w.wroteHeader && len(w.buf) == 0 {
+			// nothing was written, leave the response to the error handler
+			return nil
+		}
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+	if w.encoder == nil {
+		return nil
+	}
+	err := w.encoder.Close()
+	w.encoder.Reset(io.Discard)
+	w.pool.Put(w.encoder)
+	w.encoder = nil
+	return err
+}
+
+// negotiateEncoding picks the accepted coding with the highest quality,
+// breaking ties with the server preference order.
+func negotiateEncoding(accept string, supported []string) string {
+	if accept == "" {
+		return ""
+	}
+	type candidate struct {
+		name string
+		q    float64
+		rank int
+	}
+	var candidates []candidate
+	wildcard := -1.0
+	explicit := make(map[string]bool)
+	for _, part := range strings.Split(accept, ",") {
+		name, q := parseQuality(part)
+		if name == "*" {
+			wildcard = q
+			continue
+		}
+		explicit[name] = true
+		for rank, s := range supported {
+			if s == name && q > 0 {
+				candidates = append(candidates, candidate{name, q, rank})
+			}
+		}
+	}
+	if wildcard > 0 {
+		for rank, s := range supported {
+			if !explicit[s] {
+				candidates = append(candidates, candidate{s, wildcard, rank})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].q != candidates[j].q {
+			return candidates[i].q > candidates[j].q
+		}
+		return candidates[i].rank < candidates[j].rank
+	})
+	return candidates[0].name
+}
+
+func parseQuality(part string) (name string, q float64) {
+	q = 1
+	name = strings.ToLower(strings.TrimSpace(part))
+	if i := strings.IndexByte(name, ';'); i >= 0 {
+		params := name[i+1:]
+		name = strings.TrimSpace(name[:i])
+		for _, p := range strings.Split(params, ";") {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,104 @@
+package jago
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func compressRequest(g *Jago, target, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set(HeaderAcceptEncoding, accept)
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("jago ", 500)
+	g := New()
+	g.Use(Compress())
+	g.Get("/large", func(c Context) error {
+		return c.String(http.StatusOK, large)
+	})
+	g.Get("/small", func(c Context) error {
+		return c.String(http.StatusOK, "tiny")
+	})
+	g.Get("/png", func(c Context) error {
+		return c.Blob(http.StatusOK, "image/png", []byte(large))
+	})
+
+	rec := compressRequest(g, "/large", "deflate;q=0.5, gzip")
+	assert.Equal(t, gzipEncoding, rec.Header().Get(HeaderContentEncoding))
+	assert.Equal(t, HeaderAcceptEncoding, rec.Header().Get(HeaderVary))
+	assert.Equal(t, MIMETextPlainCharsetUTF8, rec.Header().Get(HeaderContentType))
+	r, err := gzip.NewReader(rec.Body)
+	assert.NoError(t, err)
+	b, _ := io.ReadAll(r)
+	assert.Equal(t, large, string(b))
+
+	rec = compressRequest(g, "/large", "gzip;q=0.1, deflate")
+	assert.Equal(t, deflateEncoding, rec.Header().Get(HeaderContentEncoding))
+	b, _ = io.ReadAll(flate.NewReader(rec.Body))
+	assert.Equal(t, large, string(b))
+
+	rec = compressRequest(g, "/small", "gzip")
+	assert.Equal(t, "", rec.Header().Get(HeaderContentEncoding))
+	assert.Equal(t, "tiny", rec.Body.String())
+
+	rec = compressRequest(g, "/png", "gzip")
+	assert.Equal(t, "", rec.Header().Get(HeaderContentEncoding))
+
+	rec = compressRequest(g, "/large", "br, gzip;q=0")
+	assert.Equal(t, "", rec.Header().Get(HeaderContentEncoding))
+	assert.Equal(t, large, rec.Body.String())
+
+	rec = compressRequest(g, "/missing", "gzip")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "\"Not Found\"\n", rec.Body.String())
+}
+
+func TestCompressFlush(t *testing.T) {
+	g := New()
+	g.Use(Compress())
+	g.Get("/stream", func(c Context) error {
+		c.Response().Header().Set(HeaderContentType, MIMETextPlain)
+		c.Response().WriteHeader(http.StatusOK)
+		for i := 0; i < 3; i++ {
+			c.Response().Write([]byte("chunk\n"))
+			c.Response().Flush()
+		}
+		return nil
+	})
+
+	rec := compressRequest(g, "/stream", "gzip")
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, gzipEncoding, rec.Header().Get(HeaderContentEncoding))
+	r, err := gzip.NewReader(rec.Body)
+	assert.NoError(t, err)
+	b, _ := io.ReadAll(r)
+	assert.Equal(t, "chunk\nchunk\nchunk\n", string(b))
+}
+
+func TestCompressPartialConfig(t *testing.T) {
+	large := strings.Repeat("jago ", 500)
+	g := New()
+	g.Use(CompressWithConfig(CompressConfig{MinLength: 100}))
+	g.Get("/large", func(c Context) error {
+		return c.String(http.StatusOK, large)
+	})
+
+	rec := compressRequest(g, "/large", "gzip")
+	assert.Equal(t, gzipEncoding, rec.Header().Get(HeaderContentEncoding))
+	assert.Less(t, rec.Body.Len(), len(large))
+	r, err := gzip.NewReader(rec.Body)
+	assert.NoError(t, err)
+	b, _ := io.ReadAll(r)
+	assert.Equal(t, large, string(b))
+}
@@ -4,16 +4,25 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"io/fs"
 	"net"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 type (
 	Context interface {
 		Request() *http.Request
+		SetRequest(r *http.Request)
+		Response() *Response
+		Jago() *Jago
 		Next() error
+		Reset(r *http.Request, w http.ResponseWriter)
+		Copy() Context
 
 		Path() string
 		Param(name string) string
@@ -26,7 +35,23 @@ type (
 		Cookie(name string) (*http.Cookie, error)
 		Cookies() []*http.Cookie
 
+		Set(key string, val interface{})
+		Get(key string) (interface{}, bool)
+		MustGet(key string) interface{}
+		Keys() []string
+		GetString(key string) string
+		GetInt(key string) int
+		GetInt64(key string) int64
+		GetFloat64(key string) float64
+		GetBool(key string) bool
+		GetTime(key string) time.Time
+		GetDuration(key string) time.Duration
+
+		Bind(i interface{}) error
 		BindJson(i interface{}) error
+		Validate(i interface{}) error
+
+		Session() *Session
 
 		HTML(code int, html string) error
 		HTMLBlob(code int, b []byte) error
@@ -40,6 +65,11 @@ type (
 
 		Blob(code int, contentType string, b []byte) error
 
+		File(file string) error
+		FileFS(name string, fsys fs.FS) error
+		Attachment(file, name string) error
+		Inline(file, name string) error
+
 		NoContent(code int) error
 		Redirect(code int, url string) error
 	}
@@ -49,17 +79,49 @@ type (
 		response *Response
 		j        *Jago
 		path     string
-		pnames   map[string]string
+		allowed  string
+		pnames   []string
+		pvalues  []string
+		matched  Mached
 		query    url.Values
 		handlers []HandlerFunc
+		fallback [1]HandlerFunc
+		chained  []HandlerFunc
+		group    *Group
 		hIndex   int
+		storeMu  sync.RWMutex
+		store    map[string]interface{}
 	}
 )
 
+// Value returns the value stored under key converted to T. The boolean is
+// false when the key is missing or holds a value of another type.
+func Value[T any](c Context, key string) (T, bool) {
+	val, ok := c.Get(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	t, ok := val.(T)
+	return t, ok
+}
+
 func (c *context) Request() *http.Request {
 	return c.request
 }
 
+func (c *context) SetRequest(r *http.Request) {
+	c.request = r
+}
+
+func (c *context) Response() *Response {
+	return c.response
+}
+
+func (c *context) Jago() *Jago {
+	return c.j
+}
+
 func (c *context) Next() error {
 	c.hIndex++
 	if c.hIndex < len(c.handlers) {
@@ -70,6 +132,89 @@ func (c *context) Next() error {
 	return nil
 }
 
+// Reset prepares a pooled context to serve a new request, keeping the
+// buffers allocated by previous ones.
+func (c *context) Reset(r *http.Request, w http.ResponseWriter) {
+	c.request = r
+	c.response.reset(w)
+	c.path = ""
+	c.allowed = ""
+	c.pnames = nil
+	c.pvalues = c.pvalues[:0]
+	c.query = nil
+	c.handlers = nil
+	c.fallback[0] = nil
+	c.hIndex = -1
+
+	c.storeMu.Lock()
+	for k := range c.store {
+		delete(c.store, k)
+	}
+	c.storeMu.Unlock()
+}
+
+// Copy returns a snapshot of the request, route params and stored values
+// that stays valid after the handler returns. Contexts are pooled and reused
+// for the next request, so goroutines outliving the handler must be given a
+// copy. The copy cannot write the response.
+func (c *context) Copy() Context {
+	cp := c.fork(c.request, nil)
+	cp.handlers = nil
+	cp.hIndex = 0
+	return cp
+}
+
+// fork copies the state needed to run the rest of the chain on another
+// goroutine, so the original context can be reused once the request ends.
+func (c *context) fork(r *http.Request, w http.ResponseWriter) *context {
+	fc := &context{
+		request:  r,
+		response: NewResponse(w),
+		j:        c.j,
+		path:     c.path,
+		allowed:  c.allowed,
+		pnames:   c.pnames,
+		pvalues:  append([]string(nil), c.pvalues...),
+		handlers: append([]HandlerFunc(nil), c.handlers...),
+		hIndex:   c.hIndex,
+	}
+	c.storeMu.RLock()
+	if len(c.store) > 0 {
+		fc.store = make(map[string]interface{}, len(c.store))
+		for k, v := range c.store {
+			fc.store[k] = v
+		}
+	}
+	c.storeMu.RUnlock()
+	return fc
+}
+
+// chain prefixes the matched handlers with the global middlewares, reusing
+// the context's buffer.
+func (c *context) chain(middlewares []HandlerFunc) {
+	var groupMiddlewares []HandlerFunc
+	if c.group != nil {
+		groupMiddlewares = c.group.middlewares
+	}
+	if len(middlewares)+len(groupMiddlewares) == 0 {
+		return
+	}
+	c.chained = append(append(append(c.chained[:0], middlewares...), groupMiddlewares...), c.handlers...)
+	c.handlers = c.chained
+}
+
+func (c *context) setParams(n *TreeNode, uri string, parts []string) {
+	c.pnames = n.paramNames
+	c.pvalues = c.pvalues[:0]
+	for _, i := range n.paramIndexes {
+		if i == wildcardParamIndex {
+			c.pvalues = append(c.pvalues, pathRemainder(uri, len(n.componentList)))
+		} else {
+			c.pvalues = append(c.pvalues, parts[i])
+		}
+	}
+}
+
 func (c *context) writeContentType(value string) {
 	header := c.response.Header()
 	if header.Get(HeaderContentType) == "" {
@@ -82,7 +227,12 @@ func (c *context) Path() string {
 }
 
 func (c *context) Param(name string) string {
-	return c.pnames[name]
+	for i, n := range c.pnames {
+		if n == name {
+			return c.pvalues[i]
+		}
+	}
+	return ""
 }
 
 func (c *context) RealIP() string {
@@ -127,6 +277,80 @@ func (c *context) Cookies() []*http.Cookie {
 	return c.request.Cookies()
 }
 
+// Set stores val under key for the rest of the request. It is safe to call
+// from goroutines spawned by the handler as long as they finish before it
+// returns; use Copy for goroutines that outlive the handler.
+func (c *context) Set(key string, val interface{}) {
+	c.storeMu.Lock()
+	defer c.storeMu.Unlock()
+	if c.store == nil {
+		c.store = make(map[string]interface{})
+	}
+	c.store[key] = val
+}
+
+func (c *context) Get(key string) (val interface{}, ok bool) {
+	c.storeMu.RLock()
+	defer c.storeMu.RUnlock()
+	val, ok = c.store[key]
+	return
+}
+
+// MustGet returns the value stored under key and panics when it is missing.
+func (c *context) MustGet(key string) interface{} {
+	if val, ok := c.Get(key); ok {
+		return val
+	}
+	panic(fmt.Sprintf("key %q does not exist in context", key))
+}
+
+// Keys returns the sorted keys currently stored on the context.
+func (c *context) Keys() []string {
+	c.storeMu.RLock()
+	defer c.storeMu.RUnlock()
+	keys := make([]string, 0, len(c.store))
+	for k := range c.store {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *context) GetString(key string) (s string) {
+	s, _ = Value[string](c, key)
+	return
+}
+
+func (c *context) GetInt(key string) (i int) {
+	i, _ = Value[int](c, key)
+	return
+}
+
+func (c *context) GetInt64(key string) (i int64) {
+	i, _ = Value[int64](c, key)
+	return
+}
+
+func (c *context) GetFloat64(key string) (f float64) {
+	f, _ = Value[float64](c, key)
+	return
+}
+
+func (c *context) GetBool(key string) (b bool) {
+	b, _ = Value[bool](c, key)
+	return
+}
+
+func (c *context) GetTime(key string) (t time.Time) {
+	t, _ = Value[time.Time](c, key)
+	return
+}
+
+func (c *context) GetDuration(key string) (d time.Duration) {
+	d, _ = Value[time.Duration](c, key)
+	return
+}
+
 func (c *context) BindJson(obj interface{}) error {
 	err := json.NewDecoder(c.request.Body).Decode(obj)
 	if ute, ok := err.(*json.UnmarshalTypeError); ok {
@@ -0,0 +1,76 @@
+package jago
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextStore(t *testing.T) {
+	g := New()
+	c := g.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	_, ok := c.Get("user")
+	assert.False(t, ok)
+	assert.Panics(t, func() { c.MustGet("user") })
+
+	c.Set("user", "jago")
+	c.Set("id", 42)
+	c.Set("ttl", time.Second)
+	assert.Equal(t, "jago", c.MustGet("user"))
+	assert.Equal(t, "jago", c.GetString("user"))
+	assert.Equal(t, 42, c.GetInt("id"))
+	assert.Equal(t, "", c.GetString("id"))
+	assert.Equal(t, time.Second, c.GetDuration("ttl"))
+	assert.Equal(t, []string{"id", "ttl", "user"}, c.Keys())
+
+	id, ok := Value[int](c, "id")
+	assert.True(t, ok)
+	assert.Equal(t, 42, id)
+	_, ok = Value[string](c, "id")
+	assert.False(t, ok)
+}
+
+func TestContextStoreConcurrent(t *testing.T) {
+	g := New()
+	c := g.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.Set("n", i)
+			c.GetInt("n")
+			c.Keys()
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, []string{"n"}, c.Keys())
+}
+
+func TestContextCopy(t *testing.T) {
+	g := New()
+	seen := make(chan string, 1)
+	release := make(chan struct{})
+	g.Get("/users/:name", func(c Context) error {
+		c.Set("user", c.Param("name"))
+		if c.Param("name") == "alice" {
+			cp := c.Copy()
+			go func() {
+				<-release
+				seen <- cp.GetString("user") + " " + cp.Param("name") + " " + cp.Path()
+			}()
+		}
+		return c.NoContent(http.StatusOK)
+	})
+
+	serve(g, http.MethodGet, "/users/alice")
+	serve(g, http.MethodGet, "/users/bob")
+	close(release)
+	assert.Equal(t, "alice alice /users/:name", <-seen)
+}
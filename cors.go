@@ -0,0 +1,155 @@
+package jago
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type (
+	CORSConfig struct {
+		Skipper Skipper
+
+		// AllowOrigins lists the origins allowed to access the resource.
+		// "*" allows any origin and entries may contain "*" wildcards, as in
+		// "https://*.example.com". Defaults to []string{"*"}.
+		AllowOrigins []string
+
+		// AllowOriginFunc, when set, decides instead of AllowOrigins.
+		AllowOriginFunc func(origin string) (bool, error)
+
+		// AllowMethods is sent in preflight responses.
+		AllowMethods []string
+
+		// AllowHeaders is sent in preflight responses. When empty the headers
+		// requested by the browser are allowed.
+		AllowHeaders []string
+
+		// AllowCredentials lets the browser expose responses to credentialed
+		// requests. The actual origin is echoed instead of "*" in that case.
+		AllowCredentials bool
+
+		// ExposeHeaders lists the response headers readable by scripts.
+		ExposeHeaders []string
+
+		// MaxAge is how long, in seconds, a preflight result can be cached.
+		MaxAge int
+	}
+)
+
+var DefaultCORSConfig = CORSConfig{
+	Skipper:      DefaultSkipper,
+	AllowOrigins: []string{"*"},
+	AllowMethods: []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete},
+}
+
+// CORS handles cross-origin requests. Register it with Jago.Use or Jago.Pre
+// so preflight requests are answered even for paths without an OPTIONS
+// route.
+func CORS() HandlerFunc {
+	return CORSWithConfig(DefaultCORSConfig)
+}
+
+func CORSWithConfig(config CORSConfig) HandlerFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultCORSConfig.Skipper
+	}
+	if len(config.AllowOrigins) == 0 {
+		config.AllowOrigins = DefaultCORSConfig.AllowOrigins
+	}
+	if len(config.AllowMethods) == 0 {
+		config.AllowMethods = DefaultCORSConfig.AllowMethods
+	}
+
+	allowAll := false
+	var patterns []*regexp.Regexp
+	for _, o := range config.AllowOrigins {
+		if o == "*" {
+			allowAll = true
+			continue
+		}
+		pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(strings.ToLower(o)), `\*`, `[^/]+`) + "$"
+		patterns = append(patterns, regexp.MustCompile(pattern))
+	}
+	allowMethods := strings.Join(config.AllowMethods, ",")
+	allowHeaders := strings.Join(config.AllowHeaders, ",")
+	exposeHeaders := strings.Join(config.ExposeHeaders, ",")
+	maxAge := strconv.Itoa(config.MaxAge)
+
+	matchOrigin := func(origin string) (bool, error) {
+		if config.AllowOriginFunc != nil {
+			return config.AllowOriginFunc(origin)
+		}
+		if allowAll {
+			return true, nil
+		}
+		origin = strings.ToLower(origin)
+		for _, re := range patterns {
+			if re.MatchString(origin) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+
+		req := c.Request()
+		header := c.Response().Header()
+		origin := req.Header.Get(HeaderOrigin)
+		preflight := req.Method == http.MethodOptions && req.Header.Get(HeaderAccessControlRequestMethod) != ""
+		wildcard := allowAll && config.AllowOriginFunc == nil && !config.AllowCredentials
+
+		if !wildcard {
+			// the response depends on the Origin, caches must key on it
+			header.Add(HeaderVary, HeaderOrigin)
+		}
+		if origin == "" {
+			return c.Next()
+		}
+
+		allowed, err := matchOrigin(origin)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			if preflight {
+				return c.NoContent(http.StatusNoContent)
+			}
+			return c.Next()
+		}
+
+		if wildcard {
+			header.Set(HeaderAccessControlAllowOrigin, "*")
+		} else {
+			header.Set(HeaderAccessControlAllowOrigin, origin)
+		}
+		if config.AllowCredentials {
+			header.Set(HeaderAccessControlAllowCredentials, "true")
+		}
+
+		if !preflight {
+			if exposeHeaders != "" {
+				header.Set(HeaderAccessControlExposeHeaders, exposeHeaders)
+			}
+			return c.Next()
+		}
+
+		header.Add(HeaderVary, HeaderAccessControlRequestMethod)
+		header.Add(HeaderVary, HeaderAccessControlRequestHeaders)
+		header.Set(HeaderAccessControlAllowMethods, allowMethods)
+		if allowHeaders != "" {
+			header.Set(HeaderAccessControlAllowHeaders, allowHeaders)
+		} else if h := req.Header.Get(HeaderAccessControlRequestHeaders); h != "" {
+			header.Set(HeaderAccessControlAllowHeaders, h)
+		}
+		if config.MaxAge > 0 {
+			header.Set(HeaderAccessControlMaxAge, maxAge)
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
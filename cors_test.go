@@ -0,0 +1,81 @@
+package jago
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func corsRequest(g *Jago, method, origin string, preflight bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/users", nil)
+	if origin != "" {
+		req.Header.Set(HeaderOrigin, origin)
+	}
+	if preflight {
+		req.Header.Set(HeaderAccessControlRequestMethod, http.MethodPut)
+		req.Header.Set(HeaderAccessControlRequestHeaders, "X-Token")
+	}
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORSDefault(t *testing.T) {
+	g := New()
+	g.Use(CORS())
+	g.Get("/users", jagoHandler(http.MethodGet, "/users"))
+
+	rec := corsRequest(g, http.MethodGet, "https://any.io", false)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "*", rec.Header().Get(HeaderAccessControlAllowOrigin))
+
+	rec = corsRequest(g, http.MethodOptions, "https://any.io", true)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "GET,HEAD,PUT,PATCH,POST,DELETE", rec.Header().Get(HeaderAccessControlAllowMethods))
+	assert.Equal(t, "X-Token", rec.Header().Get(HeaderAccessControlAllowHeaders))
+}
+
+func TestCORSOrigins(t *testing.T) {
+	g := New()
+	g.Use(CORSWithConfig(CORSConfig{
+		AllowOrigins:     []string{"https://*.example.com", "https://jago.dev"},
+		AllowCredentials: true,
+		ExposeHeaders:    []string{HeaderXRequestID},
+		MaxAge:           600,
+	}))
+	g.Get("/users", jagoHandler(http.MethodGet, "/users"))
+
+	rec := corsRequest(g, http.MethodGet, "https://api.example.com", false)
+	assert.Equal(t, "https://api.example.com", rec.Header().Get(HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "true", rec.Header().Get(HeaderAccessControlAllowCredentials))
+	assert.Equal(t, HeaderXRequestID, rec.Header().Get(HeaderAccessControlExposeHeaders))
+	assert.Equal(t, []string{HeaderOrigin}, rec.Header().Values(HeaderVary))
+
+	rec = corsRequest(g, http.MethodOptions, "https://jago.dev", true)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "600", rec.Header().Get(HeaderAccessControlMaxAge))
+	assert.Equal(t, []string{HeaderOrigin, HeaderAccessControlRequestMethod, HeaderAccessControlRequestHeaders}, rec.Header().Values(HeaderVary))
+
+	rec = corsRequest(g, http.MethodGet, "https://example.com.evil.io", false)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "", rec.Header().Get(HeaderAccessControlAllowOrigin))
+
+	rec = corsRequest(g, http.MethodOptions, "https://evil.io", true)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "", rec.Header().Get(HeaderAccessControlAllowMethods))
+}
+
+func TestCORSOriginFunc(t *testing.T) {
+	g := New()
+	g.Use(CORSWithConfig(CORSConfig{
+		AllowOriginFunc: func(origin string) (bool, error) {
+			return origin == "https://ok.io", nil
+		},
+	}))
+	g.Get("/users", jagoHandler(http.MethodGet, "/users"))
+
+	assert.Equal(t, "https://ok.io", corsRequest(g, http.MethodGet, "https://ok.io", false).Header().Get(HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "", corsRequest(g, http.MethodGet, "https://no.io", false).Header().Get(HeaderAccessControlAllowOrigin))
+}
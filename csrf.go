@@ -0,0 +1,152 @@
+package jago
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type (
+	CSRFConfig struct {
+		Skipper Skipper
+
+		// TokenLength is the number of random bytes in a token. Defaults
+		// to 32.
+		TokenLength int
+
+		// TokenLookup lists where the token is read from on unsafe methods,
+		// in KeyAuth's "<source>:<name>" form. Defaults to
+		// "header:X-CSRF-Token,form:_csrf,query:_csrf".
+		TokenLookup string
+
+		// Cookie settings. CookieName defaults to "_csrf", CookiePath to "/",
+		// CookieMaxAge to 24 hours and CookieSameSite to Lax.
+		CookieName     string
+		CookieDomain   string
+		CookiePath     string
+		CookieMaxAge   time.Duration
+		CookieSecure   bool
+		CookieHTTPOnly bool
+		CookieSameSite http.SameSite
+	}
+)
+
+// CSRFKey is the Context key holding the CSRF token to embed in forms and
+// templates.
+const CSRFKey = "csrf"
+
+var DefaultCSRFConfig = CSRFConfig{
+	Skipper:        DefaultSkipper,
+	TokenLength:    32,
+	TokenLookup:    "header:" + HeaderXCSRFToken + ",form:_csrf,query:_csrf",
+	CookieName:     "_csrf",
+	CookiePath:     "/",
+	CookieMaxAge:   24 * time.Hour,
+	CookieSameSite: http.SameSiteLaxMode,
+}
+
+// CSRF protects against cross-site request forgery with a double-submit
+// cookie. Every response carries the token in a cookie, and GET, HEAD,
+// OPTIONS and TRACE are let through. Other methods must echo the cookie's
+// token, by default in the X-CSRF-Token header or the _csrf form field, or
+// get ErrForbidden.
+func CSRF() HandlerFunc {
+	return CSRFWithConfig(DefaultCSRFConfig)
+}
+
+func CSRFWithConfig(config CSRFConfig) HandlerFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultCSRFConfig.Skipper
+	}
+	if config.TokenLength == 0 {
+		config.TokenLength = DefaultCSRFConfig.TokenLength
+	}
+	if config.TokenLookup == "" {
+		config.TokenLookup = DefaultCSRFConfig.TokenLookup
+	}
+	if config.CookieName == "" {
+		config.CookieName = DefaultCSRFConfig.CookieName
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = DefaultCSRFConfig.CookiePath
+	}
+	if config.CookieMaxAge == 0 {
+		config.CookieMaxAge = DefaultCSRFConfig.CookieMaxAge
+	}
+	if config.CookieSameSite == 0 {
+		config.CookieSameSite = DefaultCSRFConfig.CookieSameSite
+	}
+	extractors := csrfExtractors(config.TokenLookup)
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+
+		var token string
+		if cookie, err := c.Cookie(config.CookieName); err == nil && cookie.Value != "" {
+			token = cookie.Value
+		} else {
+			token = randomToken(config.TokenLength)
+		}
+
+		switch c.Request().Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		default:
+			if !validCSRFToken(c, extractors, token) {
+				return ErrForbidden
+			}
+		}
+
+		c.Response().SetCookie(&http.Cookie{
+			Name:     config.CookieName,
+			Value:    token,
+			Path:     config.CookiePath,
+			Domain:   config.CookieDomain,
+			Expires:  time.Now().Add(config.CookieMaxAge),
+			Secure:   config.CookieSecure,
+			HttpOnly: config.CookieHTTPOnly,
+			SameSite: config.CookieSameSite,
+		})
+		c.Response().Header().Add(HeaderVary, HeaderCookie)
+		c.Set(CSRFKey, token)
+		return c.Next()
+	}
+}
+
+// csrfExtractors parses a lookup in KeyAuth's form, with "form:<name>"
+// reading a field of the request body as well.
+func csrfExtractors(lookup string) []keyExtractor {
+	var extractors []keyExtractor
+	for _, source := range strings.Split(lookup, ",") {
+		source = strings.TrimSpace(source)
+		if name := strings.TrimPrefix(source, "form:"); name != source && name != "" {
+			extractors = append(extractors, func(c Context) string {
+				return c.Request().PostFormValue(name)
+			})
+			continue
+		}
+		extractors = append(extractors, keyExtractors(source, "")...)
+	}
+	return extractors
+}
+
+func validCSRFToken(c Context, extractors []keyExtractor, token string) bool {
+	for _, extract := range extractors {
+		if sent := extract(c); sent != "" {
+			return subtle.ConstantTimeCompare([]byte(sent), []byte(token)) == 1
+		}
+	}
+	return false
+}
+
+func randomToken(length int) string {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		panic("jago: cannot generate token: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,88 @@
+package jago
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func csrfApp() *Jago {
+	g := New()
+	g.Use(CSRFWithConfig(CSRFConfig{
+		Skipper: func(c Context) bool {
+			return c.Path() == "/webhook"
+		},
+	}))
+	g.Get("/form", func(c Context) error {
+		return c.String(http.StatusOK, c.GetString(CSRFKey))
+	})
+	g.Post("/form", func(c Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+	g.Post("/webhook", func(c Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+	return g
+}
+
+func TestCSRF(t *testing.T) {
+	g := csrfApp()
+
+	rec := serve(g, http.MethodGet, "/form")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	cookies := rec.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	token := cookies[0].Value
+	assert.Equal(t, token, rec.Body.String())
+	assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	assert.Equal(t, []string{HeaderCookie}, rec.Header().Values(HeaderVary))
+
+	post := func(header, form string) int {
+		req := httptest.NewRequest(http.MethodPost, "/form", strings.NewReader(form))
+		req.AddCookie(&http.Cookie{Name: "_csrf", Value: token})
+		req.Header.Set(HeaderContentType, MIMEApplicationForm)
+		if header != "" {
+			req.Header.Set(HeaderXCSRFToken, header)
+		}
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, post(token, ""))
+	assert.Equal(t, http.StatusNoContent, post("", url.Values{"_csrf": {token}}.Encode()))
+	assert.Equal(t, http.StatusForbidden, post("", ""))
+	assert.Equal(t, http.StatusForbidden, post("forged", ""))
+
+	req := httptest.NewRequest(http.MethodPost, "/form?_csrf="+url.QueryEscape(token), nil)
+	req.AddCookie(&http.Cookie{Name: "_csrf", Value: token})
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestCSRFWithoutCookie(t *testing.T) {
+	g := csrfApp()
+
+	req := httptest.NewRequest(http.MethodPost, "/form", nil)
+	req.Header.Set(HeaderXCSRFToken, "guess")
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	assert.Equal(t, http.StatusNoContent, serve(g, http.MethodPost, "/webhook").Code)
+}
+
+func TestCSRFTokenReused(t *testing.T) {
+	g := csrfApp()
+
+	req := httptest.NewRequest(http.MethodGet, "/form", nil)
+	req.AddCookie(&http.Cookie{Name: "_csrf", Value: "existing"})
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, "existing", rec.Body.String())
+}
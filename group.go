@@ -11,6 +11,9 @@ type (
 	}
 )
 
+// Use adds middlewares to the group. They are resolved when a request is
+// dispatched, so routes registered on the group before the call run them
+// as well.
 func (g *Group) Use(middlewares ...HandlerFunc) {
 	g.middlewares = append(g.middlewares, middlewares...)
 }
@@ -56,6 +59,5 @@ func (g *Group) Any(path string, handlers ...HandlerFunc) {
 }
 
 func (g *Group) Add(method, path string, handlers ...HandlerFunc) {
-	allHandlers := append(g.middlewares, handlers...)
-	g.j.Add(method, g.prefix+path, allHandlers...)
+	g.j.router.add(method, g.prefix+path, g, handlers...)
 }
@@ -4,21 +4,45 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sync"
 )
 
 type (
 	Jago struct {
 		router           *Router
+		premiddlewares   []HandlerFunc
+		preHandlers      []HandlerFunc
 		middlewares      []HandlerFunc
 		HTTPErrorHandler HTTPErrorHandler
-		Debug            bool
+		// MethodNotAllowedHandler runs when the path matches a route but not
+		// for the request method. The Allow header is already set.
+		MethodNotAllowedHandler HandlerFunc
+		// AutoHead serves HEAD requests with the GET route of the same path
+		// when no HEAD route is registered. The body is discarded.
+		AutoHead bool
+		// AutoOptions answers OPTIONS requests with the Allow header when no
+		// OPTIONS route is registered.
+		AutoOptions bool
+		// Validator is used by Context.Validate, and by Context.Bind when
+		// AutoValidate is set.
+		Validator    Validator
+		AutoValidate bool
+		Debug        bool
+		lifecycle    lifecycle
+		pool         sync.Pool
 	}
 
 	HTTPError struct {
 		Code    int         `json:"-"`
 		Message interface{} `json:"message"`
+		// Internal keeps the underlying error for logging; it is never
+		// sent to the client.
+		Internal error `json:"-"`
 	}
 
+	// Option configures a Jago instance created by New.
+	Option func(*Jago)
+
 	HandlerFunc      func(c Context) error
 	HTTPErrorHandler func(error, Context)
 )
@@ -32,28 +56,68 @@ func NewHTTPError(code int, message ...interface{}) *HTTPError {
 }
 
 func (he *HTTPError) Error() string {
+	if he.Internal != nil {
+		return fmt.Sprintf("code=%d, message=%v, internal=%v", he.Code, he.Message, he.Internal)
+	}
 	return fmt.Sprintf("code=%d, message=%v", he.Code, he.Message)
 }
 
-func New() *Jago {
+func (he *HTTPError) Unwrap() error {
+	return he.Internal
+}
+
+// WithRecover installs the Recover middleware ahead of every other one, so
+// panics anywhere in the chain end up in HTTPErrorHandler.
+func WithRecover() Option {
+	return func(j *Jago) {
+		j.Pre(Recover())
+	}
+}
+
+func New(options ...Option) *Jago {
 	log.Printf(banner, Version)
 	j := &Jago{
 		router: newRouter(),
 	}
 	j.HTTPErrorHandler = j.DefaultHTTPErrorHandler
+	j.MethodNotAllowedHandler = MethodNotAllowedHandler
+	j.pool.New = func() interface{} {
+		return j.allocContext()
+	}
+	for _, option := range options {
+		option(j)
+	}
 
 	return j
 }
 
 func (j *Jago) NewContext(r *http.Request, w http.ResponseWriter) Context {
+	c := j.allocContext()
+	c.Reset(r, w)
+	return c
+}
+
+func (j *Jago) allocContext() *context {
 	return &context{
-		request:  r,
-		response: NewResponse(w),
+		response: NewResponse(nil),
 		j:        j,
+		pvalues:  make([]string, 0, j.router.maxParams()),
 		hIndex:   -1,
 	}
 }
 
+// Pre adds middlewares that run before the router looks up the route, so
+// they may rewrite the request method and URL.
+func (j *Jago) Pre(middlewares ...HandlerFunc) {
+	j.premiddlewares = append(j.premiddlewares, middlewares...)
+	j.preHandlers = make([]HandlerFunc, 0, len(j.premiddlewares)+1)
+	j.preHandlers = append(j.preHandlers, j.premiddlewares...)
+	j.preHandlers = append(j.preHandlers, j.routeHandler)
+}
+
+// Use adds middlewares that run for every request, after routing and before
+// the route handlers. They also run when no route matches, so they see 404
+// and 405 responses, and they apply to routes registered before the call.
 func (j *Jago) Use(middlewares ...HandlerFunc) {
 	j.middlewares = append(j.middlewares, middlewares...)
 }
@@ -104,9 +168,10 @@ func (j *Jago) Group(prefix string, handlers ...HandlerFunc) (g *Group) {
 	return g
 }
 
+// Add registers handlers for method and path. Registering the same method
+// and path twice panics.
 func (j *Jago) Add(method, path string, handlers ...HandlerFunc) {
-	allHandlers := append(j.middlewares, handlers...)
-	j.router.add(method, path, allHandlers...)
+	j.router.add(method, path, nil, handlers...)
 }
 
 func (j *Jago) findRoute(request *http.Request, c Context) {
@@ -116,11 +181,33 @@ func (j *Jago) findRoute(request *http.Request, c Context) {
 	j.router.find(uri, method, c)
 }
 
+// route looks up the handlers for the context's request and prefixes them
+// with the global and group middlewares.
+func (j *Jago) route(ctx *context) {
+	j.findRoute(ctx.request, ctx)
+	ctx.chain(j.middlewares)
+}
+
+// routeHandler ends the Pre chain by routing the possibly rewritten request
+// and running the matched chain.
+func (j *Jago) routeHandler(c Context) error {
+	ctx := c.(*context)
+	j.route(ctx)
+	ctx.hIndex = -1
+	return ctx.Next()
+}
+
 func (j *Jago) PrintRouter() {
 	j.router.PrintTree()
 }
 
+// DefaultHTTPErrorHandler writes err as JSON. Errors raised after
+// the response is committed can no longer be sent, so they are only logged.
 func (j *Jago) DefaultHTTPErrorHandler(err error, c Context) {
+	if c.Response().Committed {
+		log.Println(err)
+		return
+	}
 	he, ok := err.(*HTTPError)
 	if !ok {
 		he = &HTTPError{
@@ -143,10 +230,21 @@ func (j *Jago) DefaultHTTPErrorHandler(err error, c Context) {
 }
 
 func (j *Jago) ServeHTTP(response http.ResponseWriter, request *http.Request) {
-	ctx := j.NewContext(request, response)
+	j.lifecycle.begin()
+	defer j.lifecycle.end()
+
+	ctx := j.pool.Get().(*context)
+	ctx.Reset(request, response)
 
-	j.findRoute(request, ctx)
+	if len(j.preHandlers) > 0 {
+		ctx.handlers = j.preHandlers
+	} else {
+		j.route(ctx)
+	}
 	if err := ctx.Next(); err != nil {
 		j.HTTPErrorHandler(err, ctx)
 	}
+	ctx.response.finish()
+
+	j.pool.Put(ctx)
 }
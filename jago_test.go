@@ -0,0 +1,121 @@
+package jago
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recordMiddleware(trace *[]string, name string) HandlerFunc {
+	return func(c Context) error {
+		*trace = append(*trace, name)
+		return c.Next()
+	}
+}
+
+func serve(g *Jago, method, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+func TestJagoUseAfterRoutes(t *testing.T) {
+	var trace []string
+	g := New()
+	g.Get("/users", func(c Context) error {
+		trace = append(trace, "handler")
+		return c.NoContent(http.StatusOK)
+	})
+	g.Use(recordMiddleware(&trace, "global"))
+
+	serve(g, http.MethodGet, "/users")
+	assert.Equal(t, []string{"global", "handler"}, trace)
+
+	trace = nil
+	rec := serve(g, http.MethodGet, "/missing")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, []string{"global"}, trace)
+
+	trace = nil
+	rec = serve(g, http.MethodPost, "/users")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, []string{"global"}, trace)
+}
+
+func TestGroupUseAfterRoutes(t *testing.T) {
+	var trace []string
+	g := New()
+	g.Use(recordMiddleware(&trace, "global"))
+	admin := g.Group("/admin", recordMiddleware(&trace, "group"))
+	admin.Get("/users", func(c Context) error {
+		trace = append(trace, "handler")
+		return c.NoContent(http.StatusOK)
+	})
+	admin.Use(recordMiddleware(&trace, "auth"))
+	g.Get("/public", func(c Context) error {
+		trace = append(trace, "public")
+		return c.NoContent(http.StatusOK)
+	})
+
+	serve(g, http.MethodGet, "/admin/users")
+	assert.Equal(t, []string{"global", "group", "auth", "handler"}, trace)
+
+	trace = nil
+	serve(g, http.MethodGet, "/public")
+	assert.Equal(t, []string{"global", "public"}, trace)
+}
+
+func TestJagoPre(t *testing.T) {
+	var trace []string
+	g := New()
+	g.Pre(func(c Context) error {
+		trace = append(trace, "pre:"+c.Path())
+		c.Request().URL.Path = "/v2" + c.Request().URL.Path
+		return c.Next()
+	})
+	g.Use(recordMiddleware(&trace, "global"))
+	g.Get("/v2/users", func(c Context) error {
+		trace = append(trace, "handler:"+c.Path())
+		return c.NoContent(http.StatusOK)
+	})
+
+	rec := serve(g, http.MethodGet, "/users")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"pre:", "global", "handler:/v2/users"}, trace)
+}
+
+func TestJagoDuplicateRoute(t *testing.T) {
+	g := New()
+	api := g.Group("/api")
+	api.Get("/a", func(c Context) error {
+		return c.String(http.StatusOK, "group")
+	})
+	assert.PanicsWithValue(t, "jago: GET /api/a is already registered", func() {
+		g.Get("/api/a", func(c Context) error {
+			return c.String(http.StatusOK, "direct")
+		})
+	})
+	assert.Panics(t, func() {
+		g.Group("/api").Get("/a", func(c Context) error { return nil })
+	})
+	assert.Panics(t, func() {
+		g.Any("/api/a", func(c Context) error { return nil })
+	})
+
+	api.Use(func(c Context) error {
+		c.Response().Header().Set("X-Group", "api")
+		return c.Next()
+	})
+	g.Post("/api/a", func(c Context) error {
+		return c.String(http.StatusOK, "direct")
+	})
+
+	rec := serve(g, http.MethodGet, "/api/a")
+	assert.Equal(t, "group", rec.Body.String())
+	assert.Equal(t, "api", rec.Header().Get("X-Group"))
+	rec = serve(g, http.MethodPost, "/api/a")
+	assert.Equal(t, "direct", rec.Body.String())
+	assert.Equal(t, "", rec.Header().Get("X-Group"))
+}
@@ -0,0 +1,380 @@
+package jago
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+type (
+	JWTConfig struct {
+		Skipper Skipper
+
+		// SigningKey verifies tokens without a matching "kid": a []byte
+		// secret of at least 32 bytes for HS256, an *rsa.PublicKey for
+		// RS256 or an *ecdsa.PublicKey on P-256 for ES256.
+		SigningKey interface{}
+
+		// SigningKeys maps a "kid" token header to its key.
+		SigningKeys map[string]interface{}
+
+		// JWKSFile is a local JSON Web Key Set whose keys are added to
+		// SigningKeys when the middleware is created.
+		JWKSFile string
+
+		// TokenLookup lists where the token is read from, in KeyAuth's
+		// "<source>:<name>" form. Defaults to "header:Authorization".
+		TokenLookup string
+
+		// AuthScheme prefixes the token in the Authorization header.
+		// Defaults to "Bearer".
+		AuthScheme string
+
+		// Issuer and Audience, when set, must match the "iss" and "aud"
+		// claims.
+		Issuer   string
+		Audience string
+
+		// Leeway tolerates clock skew when checking "exp" and "nbf".
+		Leeway time.Duration
+	}
+
+	// JWTClaims holds the decoded claims of a verified token.
+	JWTClaims map[string]interface{}
+)
+
+// JWTClaimsKey is the Context key holding the JWTClaims of the request.
+const JWTClaimsKey = "jwt_claims"
+
+// minHMACKeyLength is the smallest HS256 secret accepted, the size of the
+// hash output as required by RFC 7518.
+const minHMACKeyLength = 32
+
+var DefaultJWTConfig = JWTConfig{
+	Skipper:     DefaultSkipper,
+	TokenLookup: "header:" + HeaderAuthorization,
+	AuthScheme:  "Bearer",
+}
+
+var (
+	errJWTMalformed    = errors.New("jwt: malformed token")
+	errJWTSignature    = errors.New("jwt: invalid signature")
+	errJWTUnknownKey   = errors.New("jwt: no key for token")
+	errJWTExpired      = errors.New("jwt: token is expired")
+	errJWTNotValidYet  = errors.New("jwt: token is not valid yet")
+	errJWTIssuer       = errors.New("jwt: invalid issuer")
+	errJWTAudience     = errors.New("jwt: invalid audience")
+	errJWTMissingToken = errors.New("jwt: missing token")
+)
+
+// JWT authenticates requests with a bearer JSON Web Token signed with
+// HS256, RS256 or ES256. The claims are stored on the Context under
+// JWTClaimsKey. Failures return ErrUnauthorized with the reason as the
+// internal error.
+func JWT(key interface{}) HandlerFunc {
+	config := DefaultJWTConfig
+	config.SigningKey = key
+	return JWTWithConfig(config)
+}
+
+func JWTWithConfig(config JWTConfig) HandlerFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultJWTConfig.Skipper
+	}
+	if config.TokenLookup == "" {
+		config.TokenLookup = DefaultJWTConfig.TokenLookup
+	}
+	if config.AuthScheme == "" {
+		config.AuthScheme = DefaultJWTConfig.AuthScheme
+	}
+	keys := make(map[string]interface{}, len(config.SigningKeys))
+	for kid, key := range config.SigningKeys {
+		keys[kid] = key
+	}
+	if config.JWKSFile != "" {
+		set, err := loadJWKS(config.JWKSFile)
+		if err != nil {
+			panic(fmt.Sprintf("jago: cannot load jwks %s: %v", config.JWKSFile, err))
+		}
+		for kid, key := range set {
+			keys[kid] = key
+		}
+	}
+	if config.SigningKey == nil && len(keys) == 0 {
+		panic("jago: jwt middleware requires a signing key")
+	}
+	if err := checkHMACKey(config.SigningKey); err != nil {
+		panic("jago: jwt signing key: " + err.Error())
+	}
+	for kid, key := range keys {
+		if err := checkHMACKey(key); err != nil {
+			panic(fmt.Sprintf("jago: jwt key %q: %v", kid, err))
+		}
+	}
+	extractors := keyExtractors(config.TokenLookup, config.AuthScheme)
+
+	verify := func(token string) (JWTClaims, error) {
+		claims, err := parseJWT(token, func(kid string) interface{} {
+			if key, ok := keys[kid]; ok && kid != "" {
+				return key
+			}
+			return config.SigningKey
+		})
+		if err != nil {
+			return nil, err
+		}
+		return claims, claims.validate(config, time.Now())
+	}
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+
+		err := errJWTMissingToken
+		for _, extract := range extractors {
+			token := extract(c)
+			if token == "" {
+				continue
+			}
+			var claims JWTClaims
+			if claims, err = verify(token); err == nil {
+				c.Set(JWTClaimsKey, claims)
+				return c.Next()
+			}
+			break
+		}
+		c.Response().Header().Set(HeaderWWWAuthenticate, config.AuthScheme)
+		return &HTTPError{Code: ErrUnauthorized.Code, Message: ErrUnauthorized.Message, Internal: err}
+	}
+}
+
+// Subject returns the "sub" claim.
+func (claims JWTClaims) Subject() string {
+	s, _ := claims["sub"].(string)
+	return s
+}
+
+func (claims JWTClaims) validate(config JWTConfig, now time.Time) error {
+	exp, ok, err := claims.numericDate("exp")
+	if err != nil {
+		return err
+	}
+	if ok && !now.Before(exp.Add(config.Leeway)) {
+		return errJWTExpired
+	}
+	nbf, ok, err := claims.numericDate("nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(config.Leeway).Before(nbf) {
+		return errJWTNotValidYet
+	}
+	if config.Issuer != "" && claims["iss"] != config.Issuer {
+		return errJWTIssuer
+	}
+	if config.Audience != "" && !claims.hasAudience(config.Audience) {
+		return errJWTAudience
+	}
+	return nil
+}
+
+// numericDate reads a NumericDate claim. A claim that is present but not a
+// number makes the token malformed, so it cannot dodge the check.
+func (claims JWTClaims) numericDate(name string) (time.Time, bool, error) {
+	v, ok := claims[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}, false, errJWTMalformed
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false, errJWTMalformed
+	}
+	return time.Unix(0, int64(f*float64(time.Second))), true, nil
+}
+
+func (claims JWTClaims) hasAudience(audience string) bool {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseJWT verifies the signature of a compact JWS and decodes its claims.
+// The algorithm must agree with the type of the key, so an RSA public key
+// can never be used as an HMAC secret.
+func parseJWT(token string, keyFor func(kid string) interface{}) (JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errJWTMalformed
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errJWTMalformed
+	}
+	key := keyFor(header.Kid)
+	if key == nil {
+		return nil, errJWTUnknownKey
+	}
+	if err := verifyJWTSignature(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	var claims JWTClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return errJWTMalformed
+	}
+	dec := json.NewDecoder(strings.NewReader(string(b)))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return errJWTMalformed
+	}
+	return nil
+}
+
+func verifyJWTSignature(alg string, key interface{}, signed string, sig []byte) error {
+	hash := sha256.Sum256([]byte(signed))
+	switch alg {
+	case "HS256":
+		secret, ok := key.([]byte)
+		if !ok {
+			return errJWTUnknownKey
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return errJWTSignature
+		}
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errJWTUnknownKey
+		}
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], sig) != nil {
+			return errJWTSignature
+		}
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != elliptic.P256() {
+			return errJWTUnknownKey
+		}
+		if len(sig) != 64 {
+			return errJWTSignature
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pub, hash[:], r, s) {
+			return errJWTSignature
+		}
+	default:
+		return fmt.Errorf("jwt: unsupported algorithm %q", alg)
+	}
+	return nil
+}
+
+// checkHMACKey rejects []byte secrets short enough to be guessed, including
+// empty ones that would let anyone sign tokens.
+func checkHMACKey(key interface{}) error {
+	if secret, ok := key.([]byte); ok && len(secret) < minHMACKeyLength {
+		return fmt.Errorf("hmac secret must be at least %d bytes", minHMACKeyLength)
+	}
+	return nil
+}
+
+// loadJWKS reads the RSA, P-256 and symmetric keys of a JSON Web Key Set
+// file, indexed by "kid".
+func loadJWKS(path string) (map[string]interface{}, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Crv string `json:"crv"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		var parts [][]byte
+		for _, s := range []string{k.N, k.E, k.X, k.Y, k.K} {
+			p, err := base64.RawURLEncoding.DecodeString(s)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %v", k.Kid, err)
+			}
+			parts = append(parts, p)
+		}
+		switch k.Kty {
+		case "RSA":
+			e := new(big.Int).SetBytes(parts[1])
+			if !e.IsInt64() || e.Int64() > 1<<31-1 {
+				return nil, fmt.Errorf("key %q: invalid exponent", k.Kid)
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(parts[0]), E: int(e.Int64())}
+		case "EC":
+			if k.Crv != "P-256" {
+				return nil, fmt.Errorf("key %q: unsupported curve %q", k.Kid, k.Crv)
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(parts[2]),
+				Y:     new(big.Int).SetBytes(parts[3]),
+			}
+		case "oct":
+			if err := checkHMACKey(parts[4]); err != nil {
+				return nil, fmt.Errorf("key %q: %v", k.Kid, err)
+			}
+			keys[k.Kid] = parts[4]
+		default:
+			return nil, fmt.Errorf("key %q: unsupported key type %q", k.Kid, k.Kty)
+		}
+	}
+	return keys, nil
+}
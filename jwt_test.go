@@ -0,0 +1,186 @@
+package jago
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var jwtSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func signJWT(t *testing.T, alg, kid string, key interface{}, claims map[string]interface{}) string {
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	hash := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, hash[:])
+		assert.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, hash[:])
+		assert.NoError(t, err)
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func jwtRequest(g *Jago, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	if token != "" {
+		req.Header.Set(HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	return rec
+}
+
+func jwtApp(config JWTConfig) *Jago {
+	g := New()
+	g.Use(JWTWithConfig(config))
+	g.Get("/me", func(c Context) error {
+		claims := c.MustGet(JWTClaimsKey).(JWTClaims)
+		return c.String(http.StatusOK, claims.Subject())
+	})
+	return g
+}
+
+func TestJWTHS256(t *testing.T) {
+	secret := jwtSecret
+	g := jwtApp(JWTConfig{SigningKey: secret, Issuer: "jago", Audience: "api"})
+	now := time.Now().Unix()
+
+	rec := jwtRequest(g, signJWT(t, "HS256", "", secret, map[string]interface{}{
+		"sub": "42", "iss": "jago", "aud": []string{"web", "api"}, "exp": now + 60,
+	}))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "42", rec.Body.String())
+
+	for name, claims := range map[string]map[string]interface{}{
+		"expired":  {"iss": "jago", "aud": "api", "exp": now - 60},
+		"nbf":      {"iss": "jago", "aud": "api", "nbf": now + 60},
+		"issuer":   {"iss": "other", "aud": "api"},
+		"audience": {"iss": "jago", "aud": "web"},
+		"exp type": {"iss": "jago", "aud": "api", "exp": "1"},
+		"nbf type": {"iss": "jago", "aud": "api", "nbf": true},
+	} {
+		rec := jwtRequest(g, signJWT(t, "HS256", "", secret, claims))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, name)
+	}
+
+	rec = jwtRequest(g, signJWT(t, "HS256", "", []byte("guess"), map[string]interface{}{"iss": "jago", "aud": "api"}))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "Bearer", rec.Header().Get(HeaderWWWAuthenticate))
+	assert.Equal(t, http.StatusUnauthorized, jwtRequest(g, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, jwtRequest(g, "a.b").Code)
+}
+
+func TestJWTLeeway(t *testing.T) {
+	secret := jwtSecret
+	g := jwtApp(JWTConfig{SigningKey: secret, Leeway: time.Minute})
+	token := signJWT(t, "HS256", "", secret, map[string]interface{}{"sub": "42", "exp": time.Now().Unix() - 10})
+	assert.Equal(t, http.StatusOK, jwtRequest(g, token).Code)
+}
+
+func TestJWTAsymmetric(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	g := jwtApp(JWTConfig{SigningKeys: map[string]interface{}{
+		"rsa": &rsaKey.PublicKey,
+		"ec":  &ecKey.PublicKey,
+	}})
+	claims := map[string]interface{}{"sub": "42"}
+
+	assert.Equal(t, http.StatusOK, jwtRequest(g, signJWT(t, "RS256", "rsa", rsaKey, claims)).Code)
+	assert.Equal(t, http.StatusOK, jwtRequest(g, signJWT(t, "ES256", "ec", ecKey, claims)).Code)
+	// The algorithm must match the key type of the kid.
+	assert.Equal(t, http.StatusUnauthorized, jwtRequest(g, signJWT(t, "ES256", "rsa", ecKey, claims)).Code)
+	assert.Equal(t, http.StatusUnauthorized, jwtRequest(g, signJWT(t, "HS256", "rsa", []byte("x"), claims)).Code)
+	assert.Equal(t, http.StatusUnauthorized, jwtRequest(g, signJWT(t, "RS256", "unknown", rsaKey, claims)).Code)
+}
+
+func TestJWTJWKSFile(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	enc := base64.RawURLEncoding.EncodeToString
+	jwks := fmt.Sprintf(`{"keys":[
+		{"kty":"RSA","kid":"r1","n":%q,"e":%q},
+		{"kty":"EC","kid":"e1","crv":"P-256","x":%q,"y":%q},
+		{"kty":"oct","kid":"s1","k":%q}
+	]}`,
+		enc(rsaKey.N.Bytes()), enc([]byte{1, 0, 1}),
+		enc(ecKey.X.FillBytes(make([]byte, 32))), enc(ecKey.Y.FillBytes(make([]byte, 32))),
+		enc(jwtSecret))
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(path, []byte(jwks), 0o600))
+
+	g := New()
+	g.Use(JWTWithConfig(JWTConfig{JWKSFile: path, TokenLookup: "query:token"}))
+	g.Get("/me", func(c Context) error {
+		return c.String(http.StatusOK, c.MustGet(JWTClaimsKey).(JWTClaims).Subject())
+	})
+	claims := map[string]interface{}{"sub": "42"}
+
+	for kid, key := range map[string]interface{}{"r1": rsaKey, "e1": ecKey, "s1": jwtSecret} {
+		alg := map[string]string{"r1": "RS256", "e1": "ES256", "s1": "HS256"}[kid]
+		rec := serve(g, http.MethodGet, "/me?token="+signJWT(t, alg, kid, key, claims))
+		assert.Equal(t, http.StatusOK, rec.Code, kid)
+		assert.Equal(t, "42", rec.Body.String(), kid)
+	}
+
+	assert.Panics(t, func() { JWTWithConfig(JWTConfig{JWKSFile: filepath.Join(t.TempDir(), "missing.json")}) })
+	assert.Panics(t, func() { JWT(nil) })
+	assert.Panics(t, func() { JWT([]byte{}) })
+	assert.Panics(t, func() { JWT([]byte("secret")) })
+	assert.Panics(t, func() { JWTWithConfig(JWTConfig{SigningKeys: map[string]interface{}{"s": []byte("short")}}) })
+
+	empty := filepath.Join(t.TempDir(), "empty.json")
+	assert.NoError(t, os.WriteFile(empty, []byte(`{"keys":[{"kty":"oct","kid":"s1"}]}`), 0o600))
+	assert.Panics(t, func() { JWTWithConfig(JWTConfig{JWKSFile: empty}) })
+}
+
+func TestJWTInternalError(t *testing.T) {
+	var got error
+	g := jwtApp(JWTConfig{SigningKey: jwtSecret})
+	g.HTTPErrorHandler = func(err error, c Context) {
+		got = err
+		g.DefaultHTTPErrorHandler(err, c)
+	}
+	jwtRequest(g, signJWT(t, "HS256", "", jwtSecret, map[string]interface{}{"exp": 1}))
+	assert.True(t, errors.Is(got, errJWTExpired))
+}
@@ -0,0 +1,144 @@
+package jago
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+)
+
+type (
+	KeyAuthConfig struct {
+		Skipper Skipper
+
+		// KeyLookup lists where the key is read from, as comma separated
+		// "<source>:<name>" pairs tried in order. Sources are header, query
+		// and cookie. Defaults to "header:Authorization".
+		KeyLookup string
+
+		// AuthScheme prefixes the key in the Authorization header.
+		// Defaults to "Bearer". Other headers are read as is.
+		AuthScheme string
+
+		// Validator checks the key. It is required.
+		Validator KeyAuthValidator
+	}
+
+	// KeyAuthValidator reports whether key is valid. A non-nil error
+	// rejects the request with 401 and is kept as the internal error.
+	KeyAuthValidator func(key string, c Context) (bool, error)
+
+	keyExtractor func(c Context) string
+)
+
+// AuthKeyKey is the Context key holding the key accepted by KeyAuth.
+const AuthKeyKey = "auth_key"
+
+var DefaultKeyAuthConfig = KeyAuthConfig{
+	Skipper:    DefaultSkipper,
+	KeyLookup:  "header:" + HeaderAuthorization,
+	AuthScheme: "Bearer",
+}
+
+// KeyAuth authenticates requests with an API key, by default sent as
+// "Authorization: Bearer <key>".
+func KeyAuth(validator KeyAuthValidator) HandlerFunc {
+	config := DefaultKeyAuthConfig
+	config.Validator = validator
+	return KeyAuthWithConfig(config)
+}
+
+func KeyAuthWithConfig(config KeyAuthConfig) HandlerFunc {
+	if config.Validator == nil {
+		panic("jago: key-auth middleware requires a validator")
+	}
+	if config.Skipper == nil {
+		config.Skipper = DefaultKeyAuthConfig.Skipper
+	}
+	if config.KeyLookup == "" {
+		config.KeyLookup = DefaultKeyAuthConfig.KeyLookup
+	}
+	if config.AuthScheme == "" {
+		config.AuthScheme = DefaultKeyAuthConfig.AuthScheme
+	}
+	extractors := keyExtractors(config.KeyLookup, config.AuthScheme)
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+
+		for _, extract := range extractors {
+			key := extract(c)
+			if key == "" {
+				continue
+			}
+			valid, err := config.Validator(key, c)
+			if err != nil {
+				return &HTTPError{Code: ErrUnauthorized.Code, Message: ErrUnauthorized.Message, Internal: err}
+			}
+			if valid {
+				c.Set(AuthKeyKey, key)
+				return c.Next()
+			}
+			return ErrUnauthorized
+		}
+		return ErrUnauthorized
+	}
+}
+
+// KeyAuthKeys validates against a fixed set of keys. Every key is compared
+// in constant time.
+func KeyAuthKeys(keys ...string) KeyAuthValidator {
+	return func(key string, c Context) (bool, error) {
+		valid := 0
+		for _, k := range keys {
+			valid |= subtle.ConstantTimeCompare([]byte(key), []byte(k))
+		}
+		return valid == 1, nil
+	}
+}
+
+// keyExtractors parses a lookup such as "header:X-API-Key,query:api_key".
+// scheme is stripped from the Authorization header.
+func keyExtractors(lookup, scheme string) []keyExtractor {
+	var extractors []keyExtractor
+	for _, source := range strings.Split(lookup, ",") {
+		parts := strings.SplitN(strings.TrimSpace(source), ":", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			panic(fmt.Sprintf("jago: invalid key lookup %q", source))
+		}
+		name := parts[1]
+		switch parts[0] {
+		case "header":
+			prefix := ""
+			if strings.EqualFold(name, HeaderAuthorization) {
+				prefix = scheme + " "
+			}
+			extractors = append(extractors, func(c Context) string {
+				v := c.Request().Header.Get(name)
+				if prefix == "" {
+					return v
+				}
+				if len(v) > len(prefix) && strings.EqualFold(v[:len(prefix)], prefix) {
+					return strings.TrimSpace(v[len(prefix):])
+				}
+				return ""
+			})
+		case "query":
+			extractors = append(extractors, func(c Context) string {
+				return c.QueryParam(name)
+			})
+		case "cookie":
+			extractors = append(extractors, func(c Context) string {
+				cookie, err := c.Cookie(name)
+				if err != nil {
+					return ""
+				}
+				return cookie.Value
+			})
+		default:
+			panic(fmt.Sprintf("jago: invalid key lookup %q", source))
+		}
+	}
+	return extractors
+}
@@ -0,0 +1,64 @@
+package jago
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyAuth(t *testing.T) {
+	g := New()
+	g.Use(KeyAuth(KeyAuthKeys("k1", "k2")))
+	g.Get("/me", func(c Context) error {
+		return c.String(http.StatusOK, c.GetString(AuthKeyKey))
+	})
+
+	for auth, code := range map[string]int{
+		"Bearer k1":  http.StatusOK,
+		"bearer k2":  http.StatusOK,
+		"Bearer k3":  http.StatusUnauthorized,
+		"k1":         http.StatusUnauthorized,
+		"Basic k1":   http.StatusUnauthorized,
+		"":           http.StatusUnauthorized,
+		"Bearer  k1": http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set(HeaderAuthorization, auth)
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		assert.Equal(t, code, rec.Code, auth)
+	}
+}
+
+func TestKeyAuthLookup(t *testing.T) {
+	g := New()
+	g.Use(KeyAuthWithConfig(KeyAuthConfig{
+		KeyLookup: "header:X-API-Key,query:api_key,cookie:key",
+		Validator: KeyAuthKeys("secret"),
+	}))
+	g.Get("/me", func(c Context) error {
+		return c.String(http.StatusOK, c.GetString(AuthKeyKey))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("X-API-Key", "secret")
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, "secret", rec.Body.String())
+
+	assert.Equal(t, http.StatusOK, serve(g, http.MethodGet, "/me?api_key=secret").Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.AddCookie(&http.Cookie{Name: "key", Value: "secret"})
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	assert.Equal(t, http.StatusUnauthorized, serve(g, http.MethodGet, "/me?api_key=nope").Code)
+
+	assert.Panics(t, func() {
+		KeyAuthWithConfig(KeyAuthConfig{KeyLookup: "form:key", Validator: KeyAuthKeys("k")})
+	})
+}
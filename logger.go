@@ -0,0 +1,266 @@
+package jago
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	LoggerConfig struct {
+		Skipper Skipper
+
+		// Format is a template of ${tag} placeholders written once per
+		// request. Available tags:
+		//
+		// - time_unix, time_rfc3339, time_rfc3339_nano, time_custom
+		// - id (request ID), remote_ip, host, method, uri, path, route
+		// - protocol, referer, user_agent
+		// - status, error, latency, latency_human, bytes_in, bytes_out
+		// - header:<NAME>, query:<NAME>
+		//
+		// When empty, each record is written as a JSON object instead.
+		Format string
+
+		// CustomTimeFormat is used by the time_custom tag.
+		CustomTimeFormat string
+
+		// LogValues, when set, receives every record instead of Output, so
+		// records can be sent to any structured logger.
+		LogValues func(c Context, v LoggerValues) error
+
+		// Output defaults to os.Stdout.
+		Output io.Writer
+
+		template []loggerSegment
+		pool     *sync.Pool
+		mu       *sync.Mutex
+	}
+
+	// LoggerValues is the record produced by the Logger middleware.
+	LoggerValues struct {
+		Time         time.Time     `json:"time"`
+		ID           string        `json:"id,omitempty"`
+		RemoteIP     string        `json:"remote_ip"`
+		Host         string        `json:"host"`
+		Method       string        `json:"method"`
+		URI          string        `json:"uri"`
+		Route        string        `json:"route"`
+		Protocol     string        `json:"protocol"`
+		Status       int           `json:"status"`
+		Error        string        `json:"error,omitempty"`
+		Latency      time.Duration `json:"latency"`
+		LatencyHuman string        `json:"latency_human"`
+		BytesIn      int64         `json:"bytes_in"`
+		BytesOut     int64         `json:"bytes_out"`
+		UserAgent    string        `json:"user_agent"`
+		Referer      string        `json:"referer,omitempty"`
+	}
+
+	loggerSegment struct {
+		text string
+		tag  string
+	}
+)
+
+var DefaultLoggerConfig = LoggerConfig{
+	Skipper:          DefaultSkipper,
+	CustomTimeFormat: "2006-01-02 15:04:05.00000",
+}
+
+// Logger writes one access log record per request. Errors returned by the
+// rest of the chain are passed to HTTPErrorHandler by Logger itself, so the
+// logged status is the one sent, and are not returned to the middlewares
+// registered before it.
+func Logger() HandlerFunc {
+	return LoggerWithConfig(DefaultLoggerConfig)
+}
+
+func LoggerWithConfig(config LoggerConfig) HandlerFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultLoggerConfig.Skipper
+	}
+	if config.CustomTimeFormat == "" {
+		config.CustomTimeFormat = DefaultLoggerConfig.CustomTimeFormat
+	}
+	if config.Output == nil {
+		config.Output = os.Stdout
+	}
+	config.template = parseLoggerFormat(config.Format)
+	config.pool = &sync.Pool{
+		New: func() interface{} {
+			return bytes.NewBuffer(make([]byte, 256))
+		},
+	}
+	config.mu = new(sync.Mutex)
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+
+		start := time.Now()
+		err := c.Next()
+		if err != nil {
+			// commit the error response now so its status is logged; the
+			// error is not returned, or it would be handled a second time
+			c.Jago().HTTPErrorHandler(err, c)
+		}
+		stop := time.Now()
+
+		req := c.Request()
+		res := c.Response()
+		v := LoggerValues{
+			Time:         stop,
+			ID:           requestID(c),
+			RemoteIP:     c.RealIP(),
+			Host:         req.Host,
+			Method:       req.Method,
+			URI:          req.RequestURI,
+			Route:        c.Path(),
+			Protocol:     req.Proto,
+			Status:       res.Status,
+			Latency:      stop.Sub(start),
+			LatencyHuman: stop.Sub(start).String(),
+			BytesIn:      req.ContentLength,
+			BytesOut:     res.Size,
+			UserAgent:    req.UserAgent(),
+			Referer:      req.Referer(),
+		}
+		if !res.Committed {
+			// net/http answers 200 when nothing was written
+			v.Status = http.StatusOK
+		}
+		if v.BytesIn < 0 {
+			v.BytesIn = 0
+		}
+		if err != nil {
+			v.Error = err.Error()
+		}
+
+		if config.LogValues != nil {
+			if logErr := config.LogValues(c, v); logErr != nil {
+				return logErr
+			}
+			return nil
+		}
+
+		buf := config.pool.Get().(*bytes.Buffer)
+		buf.Reset()
+		defer config.pool.Put(buf)
+		if config.template == nil {
+			if encErr := json.NewEncoder(buf).Encode(v); encErr != nil {
+				return encErr
+			}
+		} else {
+			config.writeTemplate(buf, c, v)
+		}
+
+		config.mu.Lock()
+		_, writeErr := config.Output.Write(buf.Bytes())
+		config.mu.Unlock()
+		return writeErr
+	}
+}
+
+// requestID returns the ID set by the RequestID middleware, falling back to
+// the headers when it is not installed.
+func requestID(c Context) string {
+	if id := c.GetString(RequestIDKey); id != "" {
+		return id
+	}
+	if id := c.Response().Header().Get(HeaderXRequestID); id != "" {
+		return id
+	}
+	return c.Request().Header.Get(HeaderXRequestID)
+}
+
+func parseLoggerFormat(format string) []loggerSegment {
+	if format == "" {
+		return nil
+	}
+	var segments []loggerSegment
+	for {
+		i := strings.Index(format, "${")
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(format[i:], '}')
+		if j < 0 {
+			break
+		}
+		segments = append(segments,
+			loggerSegment{text: format[:i]},
+			loggerSegment{tag: format[i+2 : i+j]})
+		format = format[i+j+1:]
+	}
+	return append(segments, loggerSegment{text: format})
+}
+
+func (config *LoggerConfig) writeTemplate(buf *bytes.Buffer, c Context, v LoggerValues) {
+	for _, s := range config.template {
+		if s.tag == "" {
+			buf.WriteString(s.text)
+			continue
+		}
+		switch s.tag {
+		case "time_unix":
+			buf.WriteString(strconv.FormatInt(v.Time.Unix(), 10))
+		case "time_rfc3339":
+			buf.WriteString(v.Time.Format(time.RFC3339))
+		case "time_rfc3339_nano":
+			buf.WriteString(v.Time.Format(time.RFC3339Nano))
+		case "time_custom":
+			buf.WriteString(v.Time.Format(config.CustomTimeFormat))
+		case "id":
+			buf.WriteString(v.ID)
+		case "remote_ip":
+			buf.WriteString(v.RemoteIP)
+		case "host":
+			buf.WriteString(v.Host)
+		case "method":
+			buf.WriteString(v.Method)
+		case "uri":
+			buf.WriteString(v.URI)
+		case "path":
+			p := c.Request().URL.Path
+			if p == "" {
+				p = "/"
+			}
+			buf.WriteString(p)
+		case "route":
+			buf.WriteString(v.Route)
+		case "protocol":
+			buf.WriteString(v.Protocol)
+		case "referer":
+			buf.WriteString(v.Referer)
+		case "user_agent":
+			buf.WriteString(v.UserAgent)
+		case "status":
+			buf.WriteString(strconv.Itoa(v.Status))
+		case "error":
+			buf.WriteString(v.Error)
+		case "latency":
+			buf.WriteString(strconv.FormatInt(int64(v.Latency), 10))
+		case "latency_human":
+			buf.WriteString(v.LatencyHuman)
+		case "bytes_in":
+			buf.WriteString(strconv.FormatInt(v.BytesIn, 10))
+		case "bytes_out":
+			buf.WriteString(strconv.FormatInt(v.BytesOut, 10))
+		default:
+			switch {
+			case strings.HasPrefix(s.tag, "header:"):
+				buf.WriteString(c.Request().Header.Get(s.tag[7:]))
+			case strings.HasPrefix(s.tag, "query:"):
+				buf.WriteString(c.QueryParam(s.tag[6:]))
+			}
+		}
+	}
+}
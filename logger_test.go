@@ -0,0 +1,105 @@
+package jago
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New()
+	g.Use(LoggerWithConfig(LoggerConfig{Output: buf, Skipper: SkipPaths("/health")}))
+	g.Get("/users/:id", func(c Context) error {
+		return c.String(http.StatusOK, "hello")
+	})
+	g.Get("/health", jagoHandler(http.MethodGet, "/health"))
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1?x=y", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	req.Header.Set(HeaderXRequestID, "req-1")
+	g.ServeHTTP(httptest.NewRecorder(), req)
+
+	var v LoggerValues
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &v))
+	assert.Equal(t, http.MethodGet, v.Method)
+	assert.Equal(t, "/users/:id", v.Route)
+	assert.Equal(t, "/users/1?x=y", v.URI)
+	assert.Equal(t, http.StatusOK, v.Status)
+	assert.Equal(t, int64(5), v.BytesOut)
+	assert.Equal(t, "192.0.2.1", v.RemoteIP)
+	assert.Equal(t, "test-agent", v.UserAgent)
+	assert.Equal(t, "req-1", v.ID)
+
+	buf.Reset()
+	serve(g, http.MethodGet, "/health")
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestLoggerFormat(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New()
+	g.Use(LoggerWithConfig(LoggerConfig{
+		Output: buf,
+		Format: "${method} ${route} ${status} ${bytes_out} ${query:q} ${error}\n",
+	}))
+	g.Get("/missing/:id", func(c Context) error {
+		return ErrNotFound
+	})
+
+	rec := serve(g, http.MethodGet, "/missing/1?q=go")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "\"Not Found\"\n", rec.Body.String())
+	assert.Equal(t, "GET /missing/:id 404 12 go code=404, message=Not Found\n", buf.String())
+}
+
+func TestLoggerValues(t *testing.T) {
+	var got LoggerValues
+	g := New()
+	g.Use(LoggerWithConfig(LoggerConfig{
+		LogValues: func(c Context, v LoggerValues) error {
+			got = v
+			return nil
+		},
+	}))
+	g.Post("/items", func(c Context) error {
+		return c.NoContent(http.StatusCreated)
+	})
+	serve(g, http.MethodPost, "/items")
+	assert.Equal(t, http.StatusCreated, got.Status)
+	assert.Equal(t, "/items", got.Route)
+}
+
+func TestLoggerHandlesErrorOnce(t *testing.T) {
+	calls := 0
+	g := New()
+	g.HTTPErrorHandler = func(err error, c Context) {
+		calls++
+		g.DefaultHTTPErrorHandler(err, c)
+	}
+	g.Use(LoggerWithConfig(LoggerConfig{Output: new(bytes.Buffer)}))
+	g.Get("/fail", func(c Context) error {
+		return ErrBadRequest
+	})
+
+	rec := serve(g, http.MethodGet, "/fail")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, 1, calls)
+}
+
+func TestLoggerEmptyResponse(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New()
+	g.Use(LoggerWithConfig(LoggerConfig{Output: buf, Format: "${status}\n"}))
+	g.Get("/empty", func(c Context) error {
+		return nil
+	})
+
+	rec := serve(g, http.MethodGet, "/empty")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "200\n", buf.String())
+}
@@ -0,0 +1,71 @@
+package jago
+
+import (
+	"net/http"
+	"strings"
+)
+
+type (
+	MethodOverrideConfig struct {
+		Skipper Skipper
+		// Getter extracts the override method from the request.
+		Getter MethodOverrideGetter
+	}
+
+	MethodOverrideGetter func(c Context) string
+)
+
+var DefaultMethodOverrideConfig = MethodOverrideConfig{
+	Skipper: DefaultSkipper,
+	Getter:  MethodFromHeader(HeaderXHTTPMethodOverride),
+}
+
+// MethodOverride lets POST requests tunnel another method through the
+// X-HTTP-Method-Override header. It must be registered with Jago.Pre so the
+// router sees the overridden method.
+func MethodOverride() HandlerFunc {
+	return MethodOverrideWithConfig(DefaultMethodOverrideConfig)
+}
+
+func MethodOverrideWithConfig(config MethodOverrideConfig) HandlerFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultMethodOverrideConfig.Skipper
+	}
+	if config.Getter == nil {
+		config.Getter = DefaultMethodOverrideConfig.Getter
+	}
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+		req := c.Request()
+		if req.Method == http.MethodPost {
+			if m := strings.ToUpper(config.Getter(c)); m != "" {
+				req.Method = m
+			}
+		}
+		return c.Next()
+	}
+}
+
+// MethodFromHeader reads the override method from the given header.
+func MethodFromHeader(header string) MethodOverrideGetter {
+	return func(c Context) string {
+		return c.Request().Header.Get(header)
+	}
+}
+
+// MethodFromForm reads the override method from the given form field.
+func MethodFromForm(param string) MethodOverrideGetter {
+	return func(c Context) string {
+		return c.Request().FormValue(param)
+	}
+}
+
+// MethodFromQuery reads the override method from the given query param.
+func MethodFromQuery(param string) MethodOverrideGetter {
+	return func(c Context) string {
+		return c.QueryParam(param)
+	}
+}
@@ -0,0 +1,105 @@
+package jago
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type (
+	// Skipper decides whether a middleware should be bypassed for a request.
+	Skipper func(c Context) bool
+)
+
+// DefaultSkipper never skips.
+func DefaultSkipper(Context) bool {
+	return false
+}
+
+// SkipPaths skips requests whose URL path is one of paths, typically health
+// and readiness endpoints.
+func SkipPaths(paths ...string) Skipper {
+	set := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		set[p] = true
+	}
+	return func(c Context) bool {
+		return set[c.Request().URL.Path]
+	}
+}
+
+// trustedProxies holds the networks of the proxies whose forwarding headers
+// are believed.
+type trustedProxies []*net.IPNet
+
+// parseTrustedProxies accepts IPs and CIDRs and panics on anything else, as
+// it runs while the middlewares are built.
+func parseTrustedProxies(proxies []string) trustedProxies {
+	nets := make(trustedProxies, 0, len(proxies))
+	for _, p := range proxies {
+		cidr := p
+		if !strings.Contains(p, "/") {
+			if ip := net.ParseIP(p); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(fmt.Sprintf("jago: invalid trusted proxy %q", p))
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+func (t trustedProxies) contains(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range t {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the host of RemoteAddr. When that is a trusted proxy it
+// returns the last X-Forwarded-For entry not added by a trusted proxy, or
+// X-Real-Ip, since only those headers were set by someone we trust.
+func (t trustedProxies) clientIP(r *http.Request) string {
+	ip := remoteHost(r)
+	if !t.contains(ip) {
+		return ip
+	}
+	if xff := r.Header.Values(HeaderXForwardedFor); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop == "" {
+				continue
+			}
+			if ip = hop; !t.contains(hop) {
+				break
+			}
+		}
+		return ip
+	}
+	if realIP := r.Header.Get(HeaderXRealIP); realIP != "" {
+		return realIP
+	}
+	return ip
+}
+
+// remoteHost returns the IP of the peer that sent r.
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
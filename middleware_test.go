@@ -0,0 +1,72 @@
+package jago
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethodOverride(t *testing.T) {
+	g := New()
+	g.Pre(MethodOverride())
+	g.Delete("/users/:id", func(c Context) error {
+		return c.String(http.StatusOK, "deleted "+c.Param("id"))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users/1", nil)
+	req.Header.Set(HeaderXHTTPMethodOverride, "delete")
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "deleted 1", rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(HeaderXHTTPMethodOverride, "DELETE")
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestTrailingSlash(t *testing.T) {
+	g := New()
+	g.Pre(AddTrailingSlash())
+	g.Get("/docs", jagoHandler(http.MethodGet, "/docs"))
+	rec := serve(g, http.MethodGet, "/docs?page=2")
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "/docs/?page=2", rec.Header().Get(HeaderLocation))
+	assert.Equal(t, http.StatusOK, serve(g, http.MethodGet, "/docs/").Code)
+
+	g = New()
+	g.Pre(RemoveTrailingSlash())
+	g.Get("/docs", jagoHandler(http.MethodGet, "/docs"))
+	rec = serve(g, http.MethodGet, "//evil.com/")
+	assert.Equal(t, "/evil.com", rec.Header().Get(HeaderLocation))
+	assert.Equal(t, http.StatusOK, serve(g, http.MethodGet, "/docs").Code)
+
+	g = New()
+	g.Pre(RemoveTrailingSlashWithConfig(TrailingSlashConfig{}))
+	g.Get("/docs", func(c Context) error {
+		return c.String(http.StatusOK, c.Request().URL.Path)
+	})
+	rec = serve(g, http.MethodGet, "/docs/")
+	assert.Equal(t, "/docs", rec.Body.String())
+}
+
+func TestRewrite(t *testing.T) {
+	g := New()
+	g.Pre(Rewrite(
+		RewriteRule{Pattern: `^/old/(.*)$`, Replacement: "/new/$1"},
+		RewriteRule{Pattern: `^/old`, Replacement: "/never"},
+	))
+	g.Get("/new/*", func(c Context) error {
+		return c.String(http.StatusOK, c.Param("*")+" "+c.Request().RequestURI)
+	})
+	rec := serve(g, http.MethodGet, "/old/a/b?q=1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "a/b /new/a/b?q=1", rec.Body.String())
+
+	rec = serve(g, http.MethodGet, "/old/a%2Fb")
+	assert.Equal(t, "a/b /new/a/b", rec.Body.String())
+}
@@ -0,0 +1,350 @@
+package jago
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	ProxyConfig struct {
+		Skipper Skipper
+
+		// Balancer picks the upstream of each attempt. It is required.
+		Balancer ProxyBalancer
+
+		// Rewrite is the upstream path, where ":name" and "*" segments are
+		// replaced with the route params of the same name, as in
+		// "/v2/users/:id/*". The request path is kept when empty.
+		Rewrite string
+
+		// Retries is the number of extra attempts, each on the next target.
+		// Only failures to connect are retried, or any transport failure
+		// for idempotent methods, and never once the upstream answered.
+		Retries int
+
+		// RetryBodyLimit is the largest request body, in bytes, buffered so
+		// it can be sent again on a retry. Larger bodies are streamed and
+		// their requests are not retried. Defaults to 1 MB.
+		RetryBodyLimit int
+
+		// TrustedProxies lists the IPs and CIDRs of the proxies in front of
+		// the server. The X-Forwarded-* headers sent by other clients are
+		// replaced, as anyone can forge them.
+		TrustedProxies []string
+
+		// Transport sends the upstream requests. Defaults to
+		// http.DefaultTransport.
+		Transport http.RoundTripper
+
+		// ModifyResponse, when set, can change the upstream response.
+		ModifyResponse func(*http.Response) error
+	}
+
+	ProxyTarget struct {
+		Name string
+		URL  *url.URL
+		// Weight is used by the weighted balancer. Defaults to 1.
+		Weight int
+	}
+
+	// ProxyBalancer selects the target of a proxied request. It is called
+	// from concurrent requests.
+	ProxyBalancer interface {
+		Next(c Context) *ProxyTarget
+	}
+
+	roundRobinBalancer struct {
+		targets []*ProxyTarget
+		next    uint32
+	}
+
+	// proxyResponseError is returned by ModifyResponse, after the upstream
+	// answered.
+	proxyResponseError struct {
+		err error
+	}
+
+	randomBalancer struct {
+		targets []*ProxyTarget
+		mu      sync.Mutex
+		rand    *rand.Rand
+	}
+
+	// weightedBalancer implements smooth weighted round-robin: a target
+	// with weight 3 next to one with weight 1 is picked as a, a, b, a.
+	weightedBalancer struct {
+		targets []*ProxyTarget
+		mu      sync.Mutex
+		current []int
+	}
+)
+
+// Proxy forwards requests to the targets chosen by balancer. It sets the
+// X-Forwarded-For, X-Forwarded-Host and X-Forwarded-Proto headers, passes
+// WebSocket upgrades through, and returns ErrBadGateway when no upstream
+// answers.
+func Proxy(balancer ProxyBalancer) HandlerFunc {
+	return ProxyWithConfig(ProxyConfig{Balancer: balancer})
+}
+
+func ProxyWithConfig(config ProxyConfig) HandlerFunc {
+	if config.Balancer == nil {
+		panic("jago: proxy middleware requires a balancer")
+	}
+	if config.Skipper == nil {
+		config.Skipper = DefaultSkipper
+	}
+	if config.Transport == nil {
+		config.Transport = http.DefaultTransport
+	}
+	if config.RetryBodyLimit <= 0 {
+		config.RetryBodyLimit = defaultRetryBodyLimit
+	}
+	proxies := parseTrustedProxies(config.TrustedProxies)
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+
+		req := c.Request()
+		path := req.URL.Path
+		if config.Rewrite != "" {
+			path = rewritePath(config.Rewrite, c)
+		}
+		var err error
+		replayable := true
+		if config.Retries > 0 {
+			if replayable, err = bufferBody(req, config.RetryBodyLimit); err != nil {
+				if he, ok := err.(*HTTPError); ok {
+					return he
+				}
+				return &HTTPError{Code: ErrBadRequest.Code, Message: ErrBadRequest.Message, Internal: err}
+			}
+		}
+		trusted := proxies.contains(remoteHost(req))
+
+		for attempt := 0; attempt <= config.Retries; attempt++ {
+			if attempt > 0 {
+				if !replayable || !retryable(req.Method, err) || c.Response().Committed {
+					break
+				}
+				if req.GetBody != nil {
+					if req.Body, err = req.GetBody(); err != nil {
+						break
+					}
+				}
+			}
+			target := config.Balancer.Next(c)
+			if target == nil {
+				break
+			}
+			if err = proxyTo(c, target, path, trusted, config); err == nil {
+				return nil
+			}
+		}
+		return &HTTPError{Code: ErrBadGateway.Code, Message: ErrBadGateway.Message, Internal: err}
+	}
+}
+
+// defaultRetryBodyLimit is the default RetryBodyLimit.
+const defaultRetryBodyLimit = 1 << 20
+
+// proxyTo sends the request to target and reports transport errors instead
+// of writing them, so the caller may retry. The forwarding headers sent by
+// the client are kept only when it is a trusted proxy.
+func proxyTo(c Context, target *ProxyTarget, path string, trusted bool, config ProxyConfig) error {
+	var proxyErr, responseErr error
+	in := c.Request()
+	proxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = target.URL.Scheme
+			req.URL.Host = target.URL.Host
+			req.URL.Path = joinURLPath(target.URL.Path, path)
+			req.URL.RawPath = ""
+			if target.URL.RawQuery == "" || req.URL.RawQuery == "" {
+				req.URL.RawQuery = target.URL.RawQuery + req.URL.RawQuery
+			} else {
+				req.URL.RawQuery = target.URL.RawQuery + "&" + req.URL.RawQuery
+			}
+
+			if !trusted {
+				// ReverseProxy appends the peer address to a fresh list
+				req.Header.Del(HeaderXForwardedFor)
+			}
+			if !trusted || req.Header.Get(HeaderXForwardedHost) == "" {
+				req.Header.Set(HeaderXForwardedHost, in.Host)
+			}
+			if !trusted || req.Header.Get(HeaderXForwardedProto) == "" {
+				proto := "http"
+				if in.TLS != nil {
+					proto = "https"
+				}
+				req.Header.Set(HeaderXForwardedProto, proto)
+			}
+		},
+		Transport: config.Transport,
+		ModifyResponse: func(res *http.Response) error {
+			if config.ModifyResponse == nil {
+				return nil
+			}
+			responseErr = config.ModifyResponse(res)
+			return responseErr
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			proxyErr = err
+		},
+	}
+	proxy.ServeHTTP(c.Response(), in)
+	if responseErr != nil {
+		// the upstream answered, so the request must not be sent again
+		return &proxyResponseError{err: responseErr}
+	}
+	return proxyErr
+}
+
+// bufferBody reads a body of up to limit bytes into memory and sets GetBody
+// so it can be sent again. It reports whether the request can be replayed;
+// a larger body is left streaming, its first bytes put back in front.
+func bufferBody(req *http.Request, limit int) (bool, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return true, nil
+	}
+	b, err := io.ReadAll(io.LimitReader(req.Body, int64(limit)+1))
+	if err != nil {
+		return false, err
+	}
+	if len(b) > limit {
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(b), req.Body), req.Body}
+		return false, nil
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return true, nil
+}
+
+func (e *proxyResponseError) Error() string {
+	return "proxy: modify response: " + e.err.Error()
+}
+
+func (e *proxyResponseError) Unwrap() error {
+	return e.err
+}
+
+// retryable reports whether a request that failed with err may be sent
+// again: always when no connection was made, otherwise only when the method
+// is idempotent.
+func retryable(method string, err error) bool {
+	var respErr *proxyResponseError
+	if errors.As(err, &respErr) {
+		return false
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// rewritePath fills the ":name" and "*" segments of template with the
+// route params of c.
+func rewritePath(template string, c Context) string {
+	segments := strings.Split(template, "/")
+	for i, s := range segments {
+		switch {
+		case strings.HasPrefix(s, ":"):
+			segments[i] = c.Param(s[1:])
+		case s == "*":
+			segments[i] = c.Param("*")
+		case strings.HasPrefix(s, "*"):
+			segments[i] = c.Param(s[1:])
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func joinURLPath(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case strings.HasSuffix(a, "/") && strings.HasPrefix(b, "/"):
+		return a + b[1:]
+	case !strings.HasSuffix(a, "/") && !strings.HasPrefix(b, "/"):
+		return a + "/" + b
+	}
+	return a + b
+}
+
+// NewRoundRobinBalancer cycles through targets in order.
+func NewRoundRobinBalancer(targets []*ProxyTarget) ProxyBalancer {
+	return &roundRobinBalancer{targets: targets}
+}
+
+// NewRandomBalancer picks a target at random.
+func NewRandomBalancer(targets []*ProxyTarget) ProxyBalancer {
+	return &randomBalancer{targets: targets, rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// NewWeightedBalancer spreads requests according to the targets' Weight.
+func NewWeightedBalancer(targets []*ProxyTarget) ProxyBalancer {
+	return &weightedBalancer{targets: targets, current: make([]int, len(targets))}
+}
+
+func (b *roundRobinBalancer) Next(c Context) *ProxyTarget {
+	if len(b.targets) == 0 {
+		return nil
+	}
+	i := atomic.AddUint32(&b.next, 1) - 1
+	return b.targets[i%uint32(len(b.targets))]
+}
+
+func (b *randomBalancer) Next(c Context) *ProxyTarget {
+	if len(b.targets) == 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.targets[b.rand.Intn(len(b.targets))]
+}
+
+func (b *weightedBalancer) Next(c Context) *ProxyTarget {
+	if len(b.targets) == 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	total, best := 0, 0
+	for i, t := range b.targets {
+		weight := t.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		b.current[i] += weight
+		total += weight
+		if b.current[i] > b.current[best] {
+			best = i
+		}
+	}
+	b.current[best] -= total
+	return b.targets[best]
+}
@@ -0,0 +1,246 @@
+package jago
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func upstream(t *testing.T, name string) (*httptest.Server, *ProxyTarget) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s %s %s %s", name, r.URL.RequestURI(), r.Header.Get(HeaderXForwardedHost), r.Header.Get(HeaderXForwardedProto), r.Header.Get(HeaderXForwardedFor))
+	}))
+	t.Cleanup(s.Close)
+	u, _ := url.Parse(s.URL)
+	return s, &ProxyTarget{Name: name, URL: u}
+}
+
+func proxyNames(g *Jago, target string, n int) []string {
+	var names []string
+	for i := 0; i < n; i++ {
+		body := serve(g, http.MethodGet, target).Body.String()
+		names = append(names, strings.Fields(body)[0])
+	}
+	return names
+}
+
+func TestProxyRoundRobin(t *testing.T) {
+	_, a := upstream(t, "a")
+	_, b := upstream(t, "b")
+	g := New()
+	g.Any("/api/*", Proxy(NewRoundRobinBalancer([]*ProxyTarget{a, b})))
+
+	rec := serve(g, http.MethodGet, "/api/users?page=2")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "a /api/users?page=2 example.com http 192.0.2.1", rec.Body.String())
+	assert.Equal(t, []string{"b", "a", "b"}, proxyNames(g, "/api/users", 3))
+}
+
+func TestProxyWeightedAndRandom(t *testing.T) {
+	_, a := upstream(t, "a")
+	_, b := upstream(t, "b")
+	a.Weight = 3
+
+	g := New()
+	g.Get("/weighted", Proxy(NewWeightedBalancer([]*ProxyTarget{a, b})))
+	g.Get("/random", Proxy(NewRandomBalancer([]*ProxyTarget{a, b})))
+
+	assert.Equal(t, []string{"a", "a", "b", "a", "a", "a", "b", "a"}, proxyNames(g, "/weighted", 8))
+	for _, name := range proxyNames(g, "/random", 10) {
+		assert.Contains(t, []string{"a", "b"}, name)
+	}
+}
+
+func TestProxyRewrite(t *testing.T) {
+	_, a := upstream(t, "a")
+	a.URL.Path = "/base"
+	g := New()
+	g.Get("/api/users/:id/*", ProxyWithConfig(ProxyConfig{
+		Balancer: NewRoundRobinBalancer([]*ProxyTarget{a}),
+		Rewrite:  "/v2/people/:id/*",
+	}))
+
+	body := serve(g, http.MethodGet, "/api/users/42/posts/7?full=1").Body.String()
+	assert.True(t, strings.HasPrefix(body, "a /base/v2/people/42/posts/7?full=1 "), body)
+}
+
+func TestProxyRetryAndBadGateway(t *testing.T) {
+	down, dead := upstream(t, "dead")
+	down.Close()
+	_, a := upstream(t, "a")
+
+	g := New()
+	g.Get("/retry", ProxyWithConfig(ProxyConfig{
+		Balancer: NewRoundRobinBalancer([]*ProxyTarget{dead, a}),
+		Retries:  1,
+	}))
+	g.Get("/down", Proxy(NewRoundRobinBalancer([]*ProxyTarget{dead})))
+
+	rec := serve(g, http.MethodGet, "/retry")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "a "))
+
+	rec = serve(g, http.MethodGet, "/down")
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+}
+
+func TestProxyWebSocket(t *testing.T) {
+	ws := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+		line, _ := rw.ReadString('\n')
+		rw.WriteString("echo " + line)
+		rw.Flush()
+	}))
+	defer ws.Close()
+	u, _ := url.Parse(ws.URL)
+
+	status := make(chan int, 1)
+	g := New()
+	g.Use(LoggerWithConfig(LoggerConfig{
+		LogValues: func(c Context, v LoggerValues) error {
+			status <- v.Status
+			return nil
+		},
+	}))
+	g.Get("/ws", Proxy(NewRoundRobinBalancer([]*ProxyTarget{{URL: u}})))
+	front := httptest.NewServer(g)
+	defer front.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(front.URL, "http://"))
+	assert.NoError(t, err)
+	defer conn.Close()
+	fmt.Fprint(conn, "GET /ws HTTP/1.1\r\nHost: jago\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+
+	fmt.Fprint(conn, "hello\n")
+	line, err := br.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "echo hello\n", line)
+	conn.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, <-status)
+}
+
+func TestProxyForwardedHeaders(t *testing.T) {
+	_, a := upstream(t, "a")
+	g := New()
+	g.Get("/open", Proxy(NewRoundRobinBalancer([]*ProxyTarget{a})))
+	g.Get("/trusted", ProxyWithConfig(ProxyConfig{
+		Balancer:       NewRoundRobinBalancer([]*ProxyTarget{a}),
+		TrustedProxies: []string{"192.0.2.0/24"},
+	}))
+
+	forged := func(target string) string {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set(HeaderXForwardedHost, "evil.com")
+		req.Header.Set(HeaderXForwardedProto, "https")
+		req.Header.Set(HeaderXForwardedFor, "10.0.0.1")
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+	assert.Equal(t, "a /open example.com http 192.0.2.1", forged("/open"))
+	assert.Equal(t, "a /trusted evil.com https 10.0.0.1, 192.0.2.1", forged("/trusted"))
+}
+
+func TestProxyRetryIdempotent(t *testing.T) {
+	var calls int32
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer flaky.Close()
+	u, _ := url.Parse(flaky.URL)
+	_, a := upstream(t, "a")
+
+	g := New()
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		g.Add(method, "/flaky", ProxyWithConfig(ProxyConfig{
+			Balancer: NewRoundRobinBalancer([]*ProxyTarget{{URL: u}, a}),
+			Retries:  1,
+		}))
+	}
+
+	rec := serve(g, http.MethodPost, "/flaky")
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	rec = serve(g, http.MethodGet, "/flaky")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestProxyRetryBody(t *testing.T) {
+	down, dead := upstream(t, "dead")
+	down.Close()
+	echo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	}))
+	defer echo.Close()
+	u, _ := url.Parse(echo.URL)
+
+	g := New()
+	g.Post("/small", ProxyWithConfig(ProxyConfig{
+		Balancer: NewRoundRobinBalancer([]*ProxyTarget{dead, {URL: u}}),
+		Retries:  1,
+	}))
+	g.Post("/large", ProxyWithConfig(ProxyConfig{
+		Balancer:       NewRoundRobinBalancer([]*ProxyTarget{dead, {URL: u}}),
+		Retries:        1,
+		RetryBodyLimit: 4,
+	}))
+
+	post := func(target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, target, strings.NewReader(body)))
+		return rec
+	}
+	rec := post("/small", "payload")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "payload", rec.Body.String())
+
+	rec = post("/large", "payload")
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	// the next attempt goes to the echo server, with the whole body
+	rec = post("/large", "payload")
+	assert.Equal(t, "payload", rec.Body.String())
+}
+
+func TestProxyModifyResponseError(t *testing.T) {
+	var calls int32
+	_, a := upstream(t, "a")
+	_, b := upstream(t, "b")
+	g := New()
+	g.Get("/modify", ProxyWithConfig(ProxyConfig{
+		Balancer: NewRoundRobinBalancer([]*ProxyTarget{a, b}),
+		Retries:  1,
+		ModifyResponse: func(res *http.Response) error {
+			atomic.AddInt32(&calls, 1)
+			return errors.New("rejected")
+		},
+	}))
+
+	rec := serve(g, http.MethodGet, "/modify")
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,299 @@
+package jago
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	RateLimiterConfig struct {
+		Skipper Skipper
+
+		// Store keeps the counters. It is required.
+		Store RateLimiterStore
+
+		// IdentifierExtractor keys the limit. Defaults to the client IP, see
+		// TrustedProxies.
+		IdentifierExtractor func(c Context) (string, error)
+
+		// TrustedProxies lists the IPs and CIDRs of the proxies in front of
+		// the server. The default identifier is the peer address, and the
+		// X-Forwarded-For and X-Real-Ip headers are only read when the peer
+		// is one of these proxies, as any client can set them.
+		TrustedProxies []string
+	}
+
+	// RateLimiterStore consumes one request for an identifier. Implement it
+	// to keep counters in an external backend shared by several instances.
+	RateLimiterStore interface {
+		Allow(identifier string) (RateLimitResult, error)
+	}
+
+	RateLimitResult struct {
+		Allowed   bool
+		Limit     int
+		Remaining int
+		// Reset is the time until the limit is fully replenished.
+		Reset time.Duration
+		// RetryAfter is the time until the next request may succeed when
+		// Allowed is false.
+		RetryAfter time.Duration
+	}
+
+	TokenBucketConfig struct {
+		// Rate is the number of tokens added per second.
+		Rate float64
+		// Burst is the bucket size. Defaults to the rate rounded up.
+		Burst int
+		// ExpiresIn drops idle identifiers. Defaults to 3 minutes.
+		ExpiresIn time.Duration
+	}
+
+	SlidingWindowConfig struct {
+		// Limit is the number of requests allowed per Window.
+		Limit  int
+		Window time.Duration
+		// ExpiresIn drops idle identifiers. Defaults to twice the window.
+		ExpiresIn time.Duration
+	}
+
+	// memoryStore keeps per identifier state in memory and drops the
+	// entries idle for longer than expiresIn.
+	memoryStore struct {
+		mu          sync.Mutex
+		entries     map[string]*rateEntry
+		expiresIn   time.Duration
+		lastCleanup time.Time
+		now         func() time.Time
+	}
+
+	rateEntry struct {
+		lastSeen time.Time
+		// token bucket
+		tokens float64
+		// sliding window
+		start    time.Time
+		current  int
+		previous int
+	}
+
+	TokenBucketStore struct {
+		memoryStore
+		rate  float64
+		burst int
+	}
+
+	SlidingWindowStore struct {
+		memoryStore
+		limit  int
+		window time.Duration
+	}
+)
+
+// RateLimiter limits requests per peer IP with the given store. Use
+// RateLimiterWithConfig and TrustedProxies behind a reverse proxy.
+func RateLimiter(store RateLimiterStore) HandlerFunc {
+	return RateLimiterWithConfig(RateLimiterConfig{Store: store})
+}
+
+func RateLimiterWithConfig(config RateLimiterConfig) HandlerFunc {
+	if config.Store == nil {
+		panic("jago: rate limiter requires a store")
+	}
+	if config.Skipper == nil {
+		config.Skipper = DefaultSkipper
+	}
+	if config.IdentifierExtractor == nil {
+		proxies := parseTrustedProxies(config.TrustedProxies)
+		config.IdentifierExtractor = func(c Context) (string, error) {
+			return proxies.clientIP(c.Request()), nil
+		}
+	}
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+
+		identifier, err := config.IdentifierExtractor(c)
+		if err != nil {
+			return &HTTPError{Code: http.StatusForbidden, Message: http.StatusText(http.StatusForbidden), Internal: err}
+		}
+		res, err := config.Store.Allow(identifier)
+		if err != nil {
+			return &HTTPError{Code: http.StatusInternalServerError, Message: http.StatusText(http.StatusInternalServerError), Internal: err}
+		}
+
+		header := c.Response().Header()
+		header.Set(HeaderXRateLimitLimit, strconv.Itoa(res.Limit))
+		header.Set(HeaderXRateLimitRemaining, strconv.Itoa(res.Remaining))
+		header.Set(HeaderXRateLimitReset, strconv.Itoa(ceilSeconds(res.Reset)))
+		if !res.Allowed {
+			header.Set(HeaderRetryAfter, strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			return ErrTooManyRequests
+		}
+		return c.Next()
+	}
+}
+
+// RateLimitByIP keys the limit on the peer address of the connection,
+// ignoring the forwarding headers.
+func RateLimitByIP(c Context) (string, error) {
+	return trustedProxies(nil).clientIP(c.Request()), nil
+}
+
+// RateLimitByRoute keys the limit on the matched route, shared by all
+// clients.
+func RateLimitByRoute(c Context) (string, error) {
+	return c.Request().Method + " " + c.Path(), nil
+}
+
+// NewTokenBucketStore returns an in-memory store refilling Rate tokens per
+// second up to Burst.
+func NewTokenBucketStore(config TokenBucketConfig) *TokenBucketStore {
+	if config.Burst <= 0 {
+		config.Burst = int(math.Max(1, math.Ceil(config.Rate)))
+	}
+	if config.ExpiresIn <= 0 {
+		config.ExpiresIn = 3 * time.Minute
+	}
+	return &TokenBucketStore{
+		memoryStore: newMemoryStore(config.ExpiresIn),
+		rate:        config.Rate,
+		burst:       config.Burst,
+	}
+}
+
+func (s *TokenBucketStore) Allow(identifier string) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	e, ok := s.entry(identifier, now)
+	if !ok {
+		e.tokens = float64(s.burst)
+	} else {
+		e.tokens = math.Min(float64(s.burst), e.tokens+now.Sub(e.lastSeen).Seconds()*s.rate)
+	}
+	e.lastSeen = now
+
+	res := RateLimitResult{Limit: s.burst}
+	if e.tokens >= 1 {
+		e.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = s.duration(1 - e.tokens)
+	}
+	res.Remaining = int(e.tokens)
+	res.Reset = s.duration(float64(s.burst) - e.tokens)
+	return res, nil
+}
+
+func (s *TokenBucketStore) duration(tokens float64) time.Duration {
+	if s.rate <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / s.rate * float64(time.Second))
+}
+
+// NewSlidingWindowStore returns an in-memory store allowing Limit requests
+// per Window, weighting the previous window by its overlap with the
+// sliding one.
+func NewSlidingWindowStore(config SlidingWindowConfig) *SlidingWindowStore {
+	if config.Limit <= 0 {
+		config.Limit = 1
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.ExpiresIn <= 0 {
+		config.ExpiresIn = 2 * config.Window
+	}
+	return &SlidingWindowStore{
+		memoryStore: newMemoryStore(config.ExpiresIn),
+		limit:       config.Limit,
+		window:      config.Window,
+	}
+}
+
+func (s *SlidingWindowStore) Allow(identifier string) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	e, _ := s.entry(identifier, now)
+	e.lastSeen = now
+	start := now.Truncate(s.window)
+	if !e.start.Equal(start) {
+		if start.Sub(e.start) == s.window {
+			e.previous = e.current
+		} else {
+			e.previous = 0
+		}
+		e.current = 0
+		e.start = start
+	}
+
+	elapsed := now.Sub(start)
+	weight := 1 - float64(elapsed)/float64(s.window)
+	estimated := float64(e.previous)*weight + float64(e.current)
+
+	res := RateLimitResult{Limit: s.limit, Reset: s.window - elapsed}
+	if estimated+1 <= float64(s.limit) {
+		e.current++
+		estimated++
+		res.Allowed = true
+	} else {
+		res.RetryAfter = s.retryAfter(e, elapsed)
+	}
+	res.Remaining = int(math.Max(0, math.Floor(float64(s.limit)-estimated)))
+	return res, nil
+}
+
+// retryAfter estimates when the weighted previous window has decayed enough
+// to let one more request through.
+func (s *SlidingWindowStore) retryAfter(e *rateEntry, elapsed time.Duration) time.Duration {
+	room := float64(s.limit - 1 - e.current)
+	if room < 0 || e.previous == 0 {
+		return s.window - elapsed
+	}
+	at := time.Duration((1 - room/float64(e.previous)) * float64(s.window))
+	if at <= elapsed {
+		return 0
+	}
+	return at - elapsed
+}
+
+func newMemoryStore(expiresIn time.Duration) memoryStore {
+	return memoryStore{
+		entries:   make(map[string]*rateEntry),
+		expiresIn: expiresIn,
+		now:       time.Now,
+	}
+}
+
+// entry returns the state of identifier, creating it when missing, and
+// drops expired entries from time to time. The caller holds s.mu.
+func (s *memoryStore) entry(identifier string, now time.Time) (*rateEntry, bool) {
+	if now.Sub(s.lastCleanup) > s.expiresIn {
+		for id, e := range s.entries {
+			if now.Sub(e.lastSeen) > s.expiresIn {
+				delete(s.entries, id)
+			}
+		}
+		s.lastCleanup = now
+	}
+	e, ok := s.entries[identifier]
+	if !ok {
+		e = &rateEntry{}
+		s.entries[identifier] = e
+	}
+	return e, ok
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,139 @@
+package jago
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func TestTokenBucketStore(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	s := NewTokenBucketStore(TokenBucketConfig{Rate: 1, Burst: 2})
+	s.now = clock.now
+
+	r, _ := s.Allow("a")
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 1, r.Remaining)
+	r, _ = s.Allow("a")
+	assert.True(t, r.Allowed)
+	r, _ = s.Allow("a")
+	assert.False(t, r.Allowed)
+	assert.Equal(t, time.Second, r.RetryAfter)
+
+	r, _ = s.Allow("b")
+	assert.True(t, r.Allowed)
+
+	clock.t = clock.t.Add(time.Second)
+	r, _ = s.Allow("a")
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+}
+
+func TestSlidingWindowStore(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(600, 0)}
+	s := NewSlidingWindowStore(SlidingWindowConfig{Limit: 2, Window: time.Minute})
+	s.now = clock.now
+
+	r, _ := s.Allow("a")
+	assert.True(t, r.Allowed)
+	r, _ = s.Allow("a")
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+	r, _ = s.Allow("a")
+	assert.False(t, r.Allowed)
+	assert.Equal(t, time.Minute, r.RetryAfter)
+
+	// half way into the next window the previous one still weighs 1 request
+	clock.t = clock.t.Add(90 * time.Second)
+	r, _ = s.Allow("a")
+	assert.True(t, r.Allowed)
+	r, _ = s.Allow("a")
+	assert.False(t, r.Allowed)
+	assert.Equal(t, 30*time.Second, r.RetryAfter)
+
+	clock.t = clock.t.Add(time.Hour)
+	r, _ = s.Allow("a")
+	assert.True(t, r.Allowed)
+	assert.Len(t, s.entries, 1)
+}
+
+func TestRateLimiter(t *testing.T) {
+	g := New()
+	g.Use(RateLimiter(NewTokenBucketStore(TokenBucketConfig{Rate: 0.5, Burst: 1})))
+	g.Get("/users", jagoHandler(http.MethodGet, "/users"))
+
+	rec := serve(g, http.MethodGet, "/users")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get(HeaderXRateLimitLimit))
+	assert.Equal(t, "0", rec.Header().Get(HeaderXRateLimitRemaining))
+	assert.Equal(t, "2", rec.Header().Get(HeaderXRateLimitReset))
+
+	rec = serve(g, http.MethodGet, "/users")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get(HeaderRetryAfter))
+
+	// spoofed forwarding headers do not escape the limit
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(HeaderXRealIP, "10.0.0.1")
+	req.Header.Set(HeaderXForwardedFor, "10.0.0.2")
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.RemoteAddr = "192.0.2.2:1234"
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRateLimiterTrustedProxies(t *testing.T) {
+	g := New()
+	g.Use(RateLimiterWithConfig(RateLimiterConfig{
+		Store:          NewTokenBucketStore(TokenBucketConfig{Rate: 0.5, Burst: 1}),
+		TrustedProxies: []string{"192.0.2.1"},
+	}))
+	g.Get("/users", jagoHandler(http.MethodGet, "/users"))
+	for _, client := range []string{"1.1.1.1", "3.3.3.3"} {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set(HeaderXForwardedFor, client)
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code, client)
+	}
+
+	proxies := parseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	tests := []struct {
+		remote, xff, realIP, want string
+	}{
+		{"203.0.113.9:1", "1.1.1.1", "2.2.2.2", "203.0.113.9"},
+		{"192.0.2.1:1", "1.1.1.1, 3.3.3.3", "", "3.3.3.3"},
+		{"192.0.2.1:1", "1.1.1.1, 3.3.3.3, 10.1.2.3", "", "3.3.3.3"},
+		{"10.0.0.5:1", "", "2.2.2.2", "2.2.2.2"},
+		{"10.0.0.5:1", "", "", "10.0.0.5"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.RemoteAddr = tt.remote
+		if tt.xff != "" {
+			req.Header.Set(HeaderXForwardedFor, tt.xff)
+		}
+		if tt.realIP != "" {
+			req.Header.Set(HeaderXRealIP, tt.realIP)
+		}
+		assert.Equal(t, tt.want, proxies.clientIP(req), tt.remote+" "+tt.xff)
+	}
+	assert.Panics(t, func() { parseTrustedProxies([]string{"proxy"}) })
+}
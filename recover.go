@@ -0,0 +1,119 @@
+package jago
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+)
+
+type (
+	RecoverConfig struct {
+		Skipper Skipper
+		// StackSize is the maximum number of stack bytes captured.
+		StackSize int
+		// DisableStackAll limits the trace to the panicking goroutine.
+		DisableStackAll bool
+		// DisablePrintStack stops the stack trace from being logged.
+		DisablePrintStack bool
+	}
+
+	// PanicError carries a panic raised on another goroutine together with
+	// the stack where it happened, so Recover can report the original
+	// frames when the panic is raised again on the handler goroutine.
+	PanicError struct {
+		Value interface{}
+		Stack []byte
+	}
+)
+
+// panicStackSize bounds the stack captured for a PanicError.
+const panicStackSize = 64 << 10
+
+var DefaultRecoverConfig = RecoverConfig{
+	Skipper:   DefaultSkipper,
+	StackSize: 4 << 10, // 4 KB
+}
+
+// Recover turns panics into a 500 HTTPError handled by HTTPErrorHandler.
+// The stack trace is logged, and only sent to the client in Debug mode.
+// http.ErrAbortHandler is re-raised so net/http aborts the response.
+func Recover() HandlerFunc {
+	return RecoverWithConfig(DefaultRecoverConfig)
+}
+
+func RecoverWithConfig(config RecoverConfig) HandlerFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultRecoverConfig.Skipper
+	}
+	if config.StackSize == 0 {
+		config.StackSize = DefaultRecoverConfig.StackSize
+	}
+
+	return func(c Context) (returnErr error) {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			var stack []byte
+			if pe, ok := r.(*PanicError); ok {
+				r = pe.Value
+				stack = pe.Stack
+				if len(stack) > config.StackSize {
+					stack = stack[:config.StackSize]
+				}
+			}
+			if r == http.ErrAbortHandler {
+				panic(r)
+			}
+			err, ok := r.(error)
+			if !ok {
+				err = fmt.Errorf("%v", r)
+			}
+			if stack == nil {
+				stack = make([]byte, config.StackSize)
+				stack = stack[:runtime.Stack(stack, !config.DisableStackAll)]
+			}
+			if !config.DisablePrintStack {
+				log.Printf("[PANIC RECOVER] %v %s\n", err, stack)
+			}
+
+			he := &HTTPError{
+				Code:     http.StatusInternalServerError,
+				Message:  http.StatusText(http.StatusInternalServerError),
+				Internal: err,
+			}
+			if c.Jago().Debug {
+				he.Message = map[string]string{
+					"message": err.Error(),
+					"stack":   string(stack),
+				}
+			}
+			returnErr = he
+		}()
+
+		return c.Next()
+	}
+}
+
+// newPanicError captures the stack of the current goroutine. It must be
+// called from the deferred function that recovered p.
+func newPanicError(p interface{}) *PanicError {
+	stack := make([]byte, panicStackSize)
+	return &PanicError{Value: p, Stack: stack[:runtime.Stack(stack, false)]}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v", e.Value)
+}
+
+// Unwrap returns the panic value when it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
@@ -0,0 +1,54 @@
+package jago
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover(t *testing.T) {
+	g := New(WithRecover())
+	var handled error
+	g.HTTPErrorHandler = func(err error, c Context) {
+		handled = err
+		g.DefaultHTTPErrorHandler(err, c)
+	}
+	g.Get("/panic", func(c Context) error {
+		panic("boom")
+	})
+
+	rec := serve(g, http.MethodGet, "/panic")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "\"Internal Server Error\"\n", rec.Body.String())
+	assert.EqualError(t, errors.Unwrap(handled), "boom")
+}
+
+func TestRecoverDebug(t *testing.T) {
+	g := New()
+	g.Debug = true
+	g.Use(RecoverWithConfig(RecoverConfig{DisablePrintStack: true}))
+	g.Get("/panic", func(c Context) error {
+		panic(errors.New("boom"))
+	})
+
+	rec := serve(g, http.MethodGet, "/panic")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "boom", body["message"])
+	assert.True(t, strings.Contains(body["stack"], "goroutine"))
+}
+
+func TestRecoverAbortHandler(t *testing.T) {
+	g := New(WithRecover())
+	g.Get("/abort", func(c Context) error {
+		panic(http.ErrAbortHandler)
+	})
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		serve(g, http.MethodGet, "/abort")
+	})
+}
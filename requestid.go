@@ -0,0 +1,99 @@
+package jago
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+type (
+	RequestIDConfig struct {
+		Skipper Skipper
+
+		// Generator creates an ID when the request does not carry one.
+		Generator func() string
+
+		// Header carries the request ID in both directions.
+		// Defaults to X-Request-Id.
+		Header string
+
+		// IgnoreIncoming always generates a new ID instead of trusting the
+		// one sent by the client or an upstream proxy.
+		IgnoreIncoming bool
+	}
+)
+
+// RequestIDKey is the Context key holding the request ID.
+const RequestIDKey = "request_id"
+
+// maxRequestIDLength bounds incoming IDs so clients cannot flood the logs.
+const maxRequestIDLength = 128
+
+var DefaultRequestIDConfig = RequestIDConfig{
+	Skipper:   DefaultSkipper,
+	Generator: generateRequestID,
+	Header:    HeaderXRequestID,
+}
+
+// RequestID reuses the incoming X-Request-Id, or X-Correlation-Id, or
+// generates a new one. The ID is stored on the Context under RequestIDKey,
+// echoed in the response header and picked up by Logger.
+func RequestID() HandlerFunc {
+	return RequestIDWithConfig(DefaultRequestIDConfig)
+}
+
+func RequestIDWithConfig(config RequestIDConfig) HandlerFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultRequestIDConfig.Skipper
+	}
+	if config.Generator == nil {
+		config.Generator = DefaultRequestIDConfig.Generator
+	}
+	if config.Header == "" {
+		config.Header = DefaultRequestIDConfig.Header
+	}
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+
+		var id string
+		if !config.IgnoreIncoming {
+			req := c.Request()
+			id = req.Header.Get(config.Header)
+			if id == "" {
+				id = req.Header.Get(HeaderXCorrelationID)
+			}
+			if !validRequestID(id) {
+				id = ""
+			}
+		}
+		if id == "" {
+			id = config.Generator()
+		}
+
+		c.Set(RequestIDKey, id)
+		c.Response().Header().Set(config.Header, id)
+		return c.Next()
+	}
+}
+
+func generateRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("jago: cannot generate request id: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+func validRequestID(id string) bool {
+	if len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
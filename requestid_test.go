@@ -0,0 +1,49 @@
+package jago
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	logs := new(bytes.Buffer)
+	g := New()
+	g.Use(RequestIDWithConfig(RequestIDConfig{Generator: func() string { return "generated" }}))
+	g.Use(LoggerWithConfig(LoggerConfig{Output: logs, Format: "${id}\n"}))
+	g.Get("/users", func(c Context) error {
+		return c.String(http.StatusOK, c.GetString(RequestIDKey))
+	})
+
+	rec := serve(g, http.MethodGet, "/users")
+	assert.Equal(t, "generated", rec.Body.String())
+	assert.Equal(t, "generated", rec.Header().Get(HeaderXRequestID))
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(HeaderXCorrelationID, "corr-1")
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, "corr-1", rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(HeaderXRequestID, strings.Repeat("x", 200))
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, "generated", rec.Body.String())
+
+	assert.Equal(t, "generated\ncorr-1\ngenerated\n", logs.String())
+}
+
+func TestRequestIDErrorResponse(t *testing.T) {
+	g := New()
+	g.Use(RequestID())
+	rec := serve(g, http.MethodGet, "/missing")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	assert.Equal(t, "\"Not Found\"\n", rec.Body.String())
+	assert.Len(t, rec.Header().Get(HeaderXRequestID), 32)
+}
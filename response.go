@@ -1,8 +1,12 @@
 package jago
 
 import (
+	"bufio"
+	"errors"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
 )
 
 type (
@@ -11,6 +15,8 @@ type (
 		Status    int
 		Size      int64
 		Committed bool
+		discard   bool
+		before    []func()
 	}
 )
 
@@ -18,6 +24,21 @@ func NewResponse(w http.ResponseWriter) (r *Response) {
 	return &Response{Writer: w}
 }
 
+func (r *Response) reset(w http.ResponseWriter) {
+	r.Writer = w
+	r.Status = 0
+	r.Size = 0
+	r.Committed = false
+	r.discard = false
+	r.before = r.before[:0]
+}
+
+// Before registers fn to run just before the response is committed, while
+// headers can still be changed.
+func (r *Response) Before(fn func()) {
+	r.before = append(r.before, fn)
+}
+
 func (r *Response) Header() http.Header {
 	return r.Writer.Header()
 }
@@ -27,9 +48,16 @@ func (r *Response) WriteHeader(code int) {
 		log.Println("response already committed")
 		return
 	}
+	for _, fn := range r.before {
+		fn()
+	}
 	r.Status = code
-	r.Writer.WriteHeader(r.Status)
 	r.Committed = true
+	if r.discard {
+		// the header is sent by finish once the body size is known
+		return
+	}
+	r.Writer.WriteHeader(r.Status)
 }
 
 func (r *Response) SetHeader(key string, val string) {
@@ -43,6 +71,10 @@ func (r *Response) Write(b []byte) (n int, err error) {
 		}
 		r.WriteHeader(r.Status)
 	}
+	if r.discard {
+		r.Size += int64(len(b))
+		return len(b), nil
+	}
 	n, err = r.Writer.Write(b)
 	r.Size += int64(n)
 	return
@@ -53,5 +85,38 @@ func (r *Response) SetCookie(cookie *http.Cookie) {
 }
 
 func (r *Response) Flush() {
+	if r.discard {
+		return
+	}
 	r.Writer.(http.Flusher).Flush()
 }
+
+// Hijack lets the handler take over the connection, e.g. to proxy a
+// WebSocket. The response counts as committed afterwards, with status 101
+// unless a status was already sent.
+func (r *Response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := r.Writer.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("jago: response writer does not support hijacking")
+	}
+	conn, rw, err := hj.Hijack()
+	if err == nil {
+		if !r.Committed {
+			r.Status = http.StatusSwitchingProtocols
+		}
+		r.Committed = true
+	}
+	return conn, rw, err
+}
+
+// finish sends the header held back while discarding the body of an
+// automatic HEAD response, advertising the size the GET body would have had.
+func (r *Response) finish() {
+	if !r.discard || !r.Committed {
+		return
+	}
+	if r.Size > 0 && r.Header().Get(HeaderContentLength) == "" {
+		r.Header().Set(HeaderContentLength, strconv.FormatInt(r.Size, 10))
+	}
+	r.Writer.WriteHeader(r.Status)
+}
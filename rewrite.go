@@ -0,0 +1,57 @@
+package jago
+
+import (
+	"regexp"
+)
+
+type (
+	RewriteConfig struct {
+		Skipper Skipper
+		// Rules are tried in order and the first matching one is applied.
+		Rules []RewriteRule
+	}
+
+	// RewriteRule replaces the request path matching Pattern, a regular
+	// expression, with Replacement, which may reference groups as $1.
+	RewriteRule struct {
+		Pattern     string
+		Replacement string
+	}
+
+	rewriteRule struct {
+		re          *regexp.Regexp
+		replacement string
+	}
+)
+
+// Rewrite rewrites the request path before routing. Register it with
+// Jago.Pre. It panics when a rule pattern does not compile.
+func Rewrite(rules ...RewriteRule) HandlerFunc {
+	return RewriteWithConfig(RewriteConfig{Rules: rules})
+}
+
+func RewriteWithConfig(config RewriteConfig) HandlerFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultSkipper
+	}
+	rules := make([]rewriteRule, len(config.Rules))
+	for i, r := range config.Rules {
+		rules[i] = rewriteRule{re: regexp.MustCompile(r.Pattern), replacement: r.Replacement}
+	}
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+		req := c.Request()
+		for _, r := range rules {
+			if r.re.MatchString(req.URL.Path) {
+				req.URL.Path = r.re.ReplaceAllString(req.URL.Path, r.replacement)
+				req.URL.RawPath = ""
+				req.RequestURI = req.URL.RequestURI()
+				break
+			}
+		}
+		return c.Next()
+	}
+}
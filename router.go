@@ -1,6 +1,8 @@
 package jago
 
 import (
+	"net/http"
+	"sort"
 	"strings"
 )
 
@@ -18,8 +20,8 @@ func newRouter() *Router {
 	return r
 }
 
-func (r *Router) add(method, path string, handlers ...HandlerFunc) {
-	r.routes.add(method, path, handlers...)
+func (r *Router) add(method, path string, g *Group, handlers ...HandlerFunc) {
+	r.routes.add(method, path, g, handlers...)
 }
 
 func (r *Router) PrintTree() {
@@ -28,34 +30,122 @@ func (r *Router) PrintTree() {
 
 func (r *Router) find(uri string, method string, c Context) {
 	ctx := c.(*context)
+	// the wildcard remainder keeps the trailing slash
+	raw := uri
 	uri = strings.TrimSuffix(uri, "/")
-	pathParts := getURIPaths(uri)
-	maxScore, n := r.routes.find(uri, method)
+	m := &ctx.matched
+	maxScore, n := r.routes.find(uri, method, m)
+
+	if maxScore == 0 && method == http.MethodHead && ctx.j.AutoHead {
+		if maxScore, n = r.routes.find(uri, http.MethodGet, m); maxScore > 0 {
+			method = http.MethodGet
+			ctx.response.discard = true
+		}
+	}
 
 	if maxScore > 0 {
-		ctx.pnames = n.getPathParam(pathParts)
+		ctx.setParams(n, raw, m.parts)
 		ctx.handlers = n.handlers[method]
+		ctx.group = n.groups[method]
 		ctx.path = n.path
+	} else if allowed := r.allowedMethods(uri, ctx); len(allowed) > 0 {
+		ctx.allowed = strings.Join(allowed, ", ")
+		if method == http.MethodOptions && ctx.j.AutoOptions {
+			ctx.fallback[0] = optionsHandler
+		} else {
+			ctx.fallback[0] = methodNotAllowedHandler
+		}
+		ctx.handlers = ctx.fallback[:]
+		ctx.group = nil
 	} else {
-		ctx.handlers = append(ctx.handlers, NotFoundHandler)
+		ctx.fallback[0] = NotFoundHandler
+		ctx.handlers = ctx.fallback[:]
+		ctx.group = nil
+	}
+}
+
+// maxParams is the largest number of path params any registered route has.
+func (r *Router) maxParams() int {
+	return r.routes.maxParams
+}
+
+// allowedMethods lists the methods the path can be requested with, including
+// the ones Jago answers on its own when AutoHead or AutoOptions is enabled.
+func (r *Router) allowedMethods(uri string, ctx *context) []string {
+	j := ctx.j
+	methods := r.routes.allowedMethods(uri, &ctx.matched)
+	if len(methods) == 0 {
+		return methods
+	}
+	if j.AutoHead && containsString(methods, http.MethodGet) && !containsString(methods, http.MethodHead) {
+		methods = append(methods, http.MethodHead)
+	}
+	if j.AutoOptions && !containsString(methods, http.MethodOptions) {
+		methods = append(methods, http.MethodOptions)
 	}
+	sort.Strings(methods)
+	return methods
+}
+
+// methodNotAllowedHandler advertises the methods registered for the matched
+// path before handing over to the configurable Jago.MethodNotAllowedHandler.
+func methodNotAllowedHandler(c Context) error {
+	ctx := c.(*context)
+	ctx.response.Header().Set(HeaderAllow, ctx.allowed)
+	return ctx.j.MethodNotAllowedHandler(c)
+}
+
+// optionsHandler answers OPTIONS for paths without an explicit OPTIONS route.
+func optionsHandler(c Context) error {
+	ctx := c.(*context)
+	ctx.response.Header().Set(HeaderAllow, ctx.allowed)
+	return c.NoContent(http.StatusNoContent)
 }
 
 func getURIPaths(url string) []string {
-	paths := strings.Split(url, "/")
-	return filter(paths, func(v string) bool {
-		return v != ""
-	})
+	return appendURIPaths(make([]string, 0), url)
+}
+
+// appendURIPaths appends the non-empty segments of url to dst without
+// allocating beyond the growth of dst.
+func appendURIPaths(dst []string, url string) []string {
+	start := 0
+	for i := 0; i <= len(url); i++ {
+		if i == len(url) || url[i] == '/' {
+			if i > start {
+				dst = append(dst, url[start:i])
+			}
+			start = i + 1
+		}
+	}
+	return dst
+}
+
+// pathRemainder returns what follows the first n non-empty segments of uri,
+// slashes included.
+func pathRemainder(uri string, n int) string {
+	i := 0
+	for ; n > 0; n-- {
+		for i < len(uri) && uri[i] == '/' {
+			i++
+		}
+		for i < len(uri) && uri[i] != '/' {
+			i++
+		}
+	}
+	for i < len(uri) && uri[i] == '/' {
+		i++
+	}
+	return uri[i:]
 }
 
-func filter(vs []string, f func(string) bool) []string {
-	vsf := make([]string, 0)
+func containsString(vs []string, s string) bool {
 	for _, v := range vs {
-		if f(v) {
-			vsf = append(vsf, v)
+		if v == s {
+			return true
 		}
 	}
-	return vsf
+	return false
 }
 
 func max(x, y int) int {
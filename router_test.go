@@ -1,6 +1,8 @@
 package jago
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -62,3 +64,132 @@ func TestJagoWildcard2(t *testing.T) {
 	r.find("/1/functions/funcA/hello-world", "GET", c)
 	assert.Equal(t, "/1/functions/*", c.Path())
 }
+
+func TestJagoMethodNotAllowed(t *testing.T) {
+	g := New()
+	loadJagoRoutes(g, parseAPI)
+	req := httptest.NewRequest(http.MethodPatch, "/1/users/abc", nil)
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "DELETE, GET, PUT", rec.Header().Get(HeaderAllow))
+
+	req = httptest.NewRequest(http.MethodPatch, "/1/unknown", nil)
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "", rec.Header().Get(HeaderAllow))
+
+	req = httptest.NewRequest(http.MethodPatch, "/1/users", nil)
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, POST", rec.Header().Get(HeaderAllow))
+}
+
+func TestJagoMethodNotAllowedHandler(t *testing.T) {
+	g := New()
+	g.Get("/users", jagoHandler(http.MethodGet, "/users"))
+	g.MethodNotAllowedHandler = func(c Context) error {
+		return c.String(http.StatusTeapot, "nope")
+	}
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, "GET", rec.Header().Get(HeaderAllow))
+}
+
+func TestJagoAutoHead(t *testing.T) {
+	g := New()
+	g.AutoHead = true
+	g.Get("/users/:id", func(c Context) error {
+		return c.String(http.StatusOK, "user "+c.Param("id"))
+	})
+	req := httptest.NewRequest(http.MethodHead, "/users/42", nil)
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "7", rec.Header().Get(HeaderContentLength))
+	assert.Equal(t, 0, rec.Body.Len())
+
+	req = httptest.NewRequest(http.MethodPost, "/users/42", nil)
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, HEAD", rec.Header().Get(HeaderAllow))
+}
+
+func TestJagoAutoOptions(t *testing.T) {
+	g := New()
+	g.AutoOptions = true
+	loadJagoRoutes(g, parseAPI)
+	req := httptest.NewRequest(http.MethodOptions, "/1/users/abc", nil)
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "DELETE, GET, OPTIONS, PUT", rec.Header().Get(HeaderAllow))
+
+	req = httptest.NewRequest(http.MethodOptions, "/1/unknown", nil)
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestJagoWildcardParam(t *testing.T) {
+	g := New()
+	loadJagoRoutes(g, parseAPI)
+	r := g.router
+	c := g.NewContext(nil, nil)
+	r.find("/1/functions/funcA/hello-world", "GET", c)
+	assert.Equal(t, "funcA/hello-world", c.Param("*"))
+
+	c = g.NewContext(nil, nil)
+	r.find("/1/functions", "GET", c)
+	assert.Equal(t, "/1/functions/*", c.Path())
+	assert.Equal(t, "", c.Param("*"))
+}
+
+func TestJagoNamedWildcardParam(t *testing.T) {
+	g := New()
+	g.Get("/static/:version/*filepath", jagoHandler(http.MethodGet, "/static/:version/*filepath"))
+	g.Post("/static/:version/*filepath", jagoHandler(http.MethodPost, "/static/:version/*filepath"))
+	r := g.router
+	c := g.NewContext(nil, nil)
+	r.find("/static/v1/css/Site.css", "GET", c)
+	assert.Equal(t, "/static/:version/*filepath", c.Path())
+	assert.Equal(t, "v1", c.Param("version"))
+	assert.Equal(t, "css/Site.css", c.Param("filepath"))
+	assert.Equal(t, "css/Site.css", c.Param("*"))
+
+	c = g.NewContext(nil, nil)
+	r.find("/static/v1/js/app.js", "POST", c)
+	assert.Equal(t, "js/app.js", c.Param("filepath"))
+
+	g = New()
+	g.Get("/files/*Path", jagoHandler(http.MethodGet, "/files/*Path"))
+	c = g.NewContext(nil, nil)
+	g.router.find("/files/a/b.txt", "GET", c)
+	assert.Equal(t, "a/b.txt", c.Param("Path"))
+}
+
+func TestJagoWildcardParamConflict(t *testing.T) {
+	g := New()
+	g.Get("/f/*a", jagoHandler(http.MethodGet, "/f/*a"))
+	assert.Panics(t, func() {
+		g.Post("/f/*b", jagoHandler(http.MethodPost, "/f/*b"))
+	})
+	g.Post("/f/*a", jagoHandler(http.MethodPost, "/f/*a"))
+
+	c := g.NewContext(nil, nil)
+	g.router.find("/f/x/y", "GET", c)
+	assert.Equal(t, "x/y", c.Param("a"))
+}
+
+func TestJagoWildcardTrailingSlash(t *testing.T) {
+	g := New()
+	g.Get("/w/*", jagoHandler(http.MethodGet, "/w/*"))
+	c := g.NewContext(nil, nil)
+	g.router.find("/w/a/b/", "GET", c)
+	assert.Equal(t, "a/b/", c.Param("*"))
+}
@@ -612,3 +612,47 @@ func BenchmarkJagoParseAPI(b *testing.B) {
 	loadJagoRoutes(g, parseAPI)
 	benchmarkRoutes(b, g, parseAPI)
 }
+
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(int) {}
+
+func benchmarkRouteAllocs(b *testing.B, routes []*Route, method, path string, handler HandlerFunc) {
+	g := New()
+	for _, r := range routes {
+		g.Add(r.Method, r.Path, handler)
+	}
+	r := httptest.NewRequest(method, path, nil)
+	w := &discardResponseWriter{header: make(http.Header)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.ServeHTTP(w, r)
+	}
+}
+
+func BenchmarkJagoStaticRouteAllocs(b *testing.B) {
+	benchmarkRouteAllocs(b, static, http.MethodGet, "/articles/wiki/final.go", func(c Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+}
+
+func BenchmarkJagoParamRouteAllocs(b *testing.B) {
+	benchmarkRouteAllocs(b, githubAPI, http.MethodGet, "/repos/julienschmidt/httprouter/stargazers", func(c Context) error {
+		if c.Param("owner") != "julienschmidt" || c.Param("repo") != "httprouter" {
+			return ErrBadRequest
+		}
+		return c.NoContent(http.StatusOK)
+	})
+}
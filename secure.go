@@ -0,0 +1,120 @@
+package jago
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	SecureConfig struct {
+		Skipper Skipper
+
+		// XSSProtection sets X-XSS-Protection. "0" turns off the legacy
+		// browser filter, which is the current recommendation.
+		XSSProtection string
+
+		// ContentTypeNosniff sets X-Content-Type-Options.
+		ContentTypeNosniff string
+
+		// XFrameOptions sets X-Frame-Options, e.g. "DENY" or "SAMEORIGIN".
+		XFrameOptions string
+
+		// HSTSMaxAge sets Strict-Transport-Security, in seconds. It is only
+		// sent over TLS, or behind a proxy reporting https in
+		// X-Forwarded-Proto when TrustForwardedProto is set.
+		HSTSMaxAge            int
+		HSTSExcludeSubdomains bool
+		HSTSPreload           bool
+		TrustForwardedProto   bool
+
+		// ContentSecurityPolicy sets Content-Security-Policy. Every
+		// "{nonce}" is replaced with a random per-request nonce, stored on
+		// the Context under CSPNonceKey for inline scripts and styles.
+		ContentSecurityPolicy string
+
+		// CSPReportOnly sends the policy as
+		// Content-Security-Policy-Report-Only instead.
+		CSPReportOnly bool
+
+		// ReferrerPolicy sets Referrer-Policy.
+		ReferrerPolicy string
+	}
+)
+
+// CSPNonceKey is the Context key holding the Content-Security-Policy nonce
+// of the request.
+const CSPNonceKey = "csp_nonce"
+
+const cspNoncePlaceholder = "{nonce}"
+
+var DefaultSecureConfig = SecureConfig{
+	Skipper:            DefaultSkipper,
+	XSSProtection:      "0",
+	ContentTypeNosniff: "nosniff",
+	XFrameOptions:      "SAMEORIGIN",
+	HSTSMaxAge:         31536000,
+	ReferrerPolicy:     "strict-origin-when-cross-origin",
+}
+
+// Secure sets security related response headers. Unlike other middlewares
+// SecureWithConfig does not fill in defaults: an empty field leaves its
+// header unset.
+func Secure() HandlerFunc {
+	return SecureWithConfig(DefaultSecureConfig)
+}
+
+func SecureWithConfig(config SecureConfig) HandlerFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultSecureConfig.Skipper
+	}
+
+	hsts := ""
+	if config.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", config.HSTSMaxAge)
+		if !config.HSTSExcludeSubdomains {
+			hsts += "; includeSubdomains"
+		}
+		if config.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+	cspHeader := HeaderContentSecurityPolicy
+	if config.CSPReportOnly {
+		cspHeader = HeaderContentSecurityPolicyReportOnly
+	}
+	useNonce := strings.Contains(config.ContentSecurityPolicy, cspNoncePlaceholder)
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+
+		req := c.Request()
+		header := c.Response().Header()
+		if config.XSSProtection != "" {
+			header.Set(HeaderXXSSProtection, config.XSSProtection)
+		}
+		if config.ContentTypeNosniff != "" {
+			header.Set(HeaderXContentTypeOptions, config.ContentTypeNosniff)
+		}
+		if config.XFrameOptions != "" {
+			header.Set(HeaderXFrameOptions, config.XFrameOptions)
+		}
+		if hsts != "" && (req.TLS != nil || config.TrustForwardedProto && req.Header.Get(HeaderXForwardedProto) == "https") {
+			header.Set(HeaderStrictTransportSecurity, hsts)
+		}
+		if config.ContentSecurityPolicy != "" {
+			csp := config.ContentSecurityPolicy
+			if useNonce {
+				nonce := randomToken(16)
+				c.Set(CSPNonceKey, nonce)
+				csp = strings.ReplaceAll(csp, cspNoncePlaceholder, nonce)
+			}
+			header.Set(cspHeader, csp)
+		}
+		if config.ReferrerPolicy != "" {
+			header.Set(HeaderReferrerPolicy, config.ReferrerPolicy)
+		}
+		return c.Next()
+	}
+}
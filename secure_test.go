@@ -0,0 +1,66 @@
+package jago
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecureDefault(t *testing.T) {
+	g := New()
+	g.Use(Secure())
+	g.Get("/", jagoHandler(http.MethodGet, "/"))
+
+	rec := serve(g, http.MethodGet, "/")
+	assert.Equal(t, "0", rec.Header().Get(HeaderXXSSProtection))
+	assert.Equal(t, "nosniff", rec.Header().Get(HeaderXContentTypeOptions))
+	assert.Equal(t, "SAMEORIGIN", rec.Header().Get(HeaderXFrameOptions))
+	assert.Equal(t, "strict-origin-when-cross-origin", rec.Header().Get(HeaderReferrerPolicy))
+	assert.Empty(t, rec.Header().Get(HeaderStrictTransportSecurity))
+	assert.Empty(t, rec.Header().Get(HeaderContentSecurityPolicy))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.TLS = &tls.ConnectionState{}
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, "max-age=31536000; includeSubdomains", rec.Header().Get(HeaderStrictTransportSecurity))
+
+	// X-Forwarded-Proto is ignored unless trusted.
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderXForwardedProto, "https")
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Empty(t, rec.Header().Get(HeaderStrictTransportSecurity))
+}
+
+func TestSecureConfig(t *testing.T) {
+	g := New()
+	g.Use(SecureWithConfig(SecureConfig{
+		HSTSMaxAge:            3600,
+		HSTSExcludeSubdomains: true,
+		HSTSPreload:           true,
+		TrustForwardedProto:   true,
+		ContentSecurityPolicy: "script-src 'nonce-{nonce}'",
+		CSPReportOnly:         true,
+	}))
+	g.Get("/", func(c Context) error {
+		return c.String(http.StatusOK, c.GetString(CSPNonceKey))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderXForwardedProto, "https")
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+
+	nonce := rec.Body.String()
+	assert.NotEmpty(t, nonce)
+	assert.Equal(t, "script-src 'nonce-"+nonce+"'", rec.Header().Get(HeaderContentSecurityPolicyReportOnly))
+	assert.Empty(t, rec.Header().Get(HeaderContentSecurityPolicy))
+	assert.Equal(t, "max-age=3600; preload", rec.Header().Get(HeaderStrictTransportSecurity))
+	assert.Empty(t, rec.Header().Get(HeaderXFrameOptions))
+
+	assert.NotEqual(t, nonce, serve(g, http.MethodGet, "/").Body.String())
+}
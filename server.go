@@ -0,0 +1,142 @@
+package jago
+
+import (
+	stdcontext "context"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	lifecycle struct {
+		mu sync.Mutex
+		// servers and listeners hold every started server, in start
+		// order, so Shutdown stops all of them.
+		servers   []*http.Server
+		listeners []net.Listener
+		inflight  int64
+		// closed is set by Shutdown so servers started later refuse to
+		// run.
+		closed bool
+	}
+)
+
+// shutdownPollInterval is how often Shutdown checks for in-flight requests.
+const shutdownPollInterval = 10 * time.Millisecond
+
+// Start starts an HTTP server listening on addr.
+func (j *Jago) Start(addr string) error {
+	return j.startServer(&http.Server{Addr: addr, Handler: j}, "", "")
+}
+
+// StartTLS starts an HTTPS server listening on addr with the given
+// certificate and key files.
+func (j *Jago) StartTLS(addr, certFile, keyFile string) error {
+	return j.startServer(&http.Server{Addr: addr, Handler: j}, certFile, keyFile)
+}
+
+// StartServer starts a custom http.Server. Jago is used as the handler when
+// s.Handler is nil, and TLS is served when s.TLSConfig carries certificates.
+func (j *Jago) StartServer(s *http.Server) error {
+	return j.startServer(s, "", "")
+}
+
+func (j *Jago) startServer(s *http.Server, certFile, keyFile string) error {
+	if s.Handler == nil {
+		s.Handler = j
+	}
+	addr := s.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+
+	// hold the lock until the server is registered, so a concurrent
+	// Shutdown either sees it or prevents it from starting
+	j.lifecycle.mu.Lock()
+	if j.lifecycle.closed {
+		j.lifecycle.mu.Unlock()
+		return http.ErrServerClosed
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		j.lifecycle.mu.Unlock()
+		return err
+	}
+	j.lifecycle.servers = append(j.lifecycle.servers, s)
+	j.lifecycle.listeners = append(j.lifecycle.listeners, ln)
+	j.lifecycle.mu.Unlock()
+
+	tls := certFile != "" || (s.TLSConfig != nil && (len(s.TLSConfig.Certificates) > 0 || s.TLSConfig.GetCertificate != nil))
+	if tls {
+		log.Printf("https server started on %s", ln.Addr())
+		return s.ServeTLS(ln, certFile, keyFile)
+	}
+	log.Printf("http server started on %s", ln.Addr())
+	return s.Serve(ln)
+}
+
+// ListenerAddr returns the address of the first server started, or nil when
+// none has been. Use ListenerAddrs when several servers run.
+func (j *Jago) ListenerAddr() net.Addr {
+	j.lifecycle.mu.Lock()
+	defer j.lifecycle.mu.Unlock()
+	if len(j.lifecycle.listeners) == 0 {
+		return nil
+	}
+	return j.lifecycle.listeners[0].Addr()
+}
+
+// ListenerAddrs returns the addresses of all started servers, in the order
+// they were started.
+func (j *Jago) ListenerAddrs() []net.Addr {
+	j.lifecycle.mu.Lock()
+	defer j.lifecycle.mu.Unlock()
+	addrs := make([]net.Addr, len(j.lifecycle.listeners))
+	for i, ln := range j.lifecycle.listeners {
+		addrs[i] = ln.Addr()
+	}
+	return addrs
+}
+
+// Shutdown stops every started server from accepting new connections and
+// waits until every in-flight request has finished or ctx is done,
+// whichever comes first. Servers started afterwards return
+// http.ErrServerClosed.
+func (j *Jago) Shutdown(ctx stdcontext.Context) error {
+	j.lifecycle.mu.Lock()
+	j.lifecycle.closed = true
+	servers := j.lifecycle.servers
+	j.lifecycle.mu.Unlock()
+
+	var err error
+	for _, s := range servers {
+		if shutdownErr := s.Shutdown(ctx); shutdownErr != nil && err == nil {
+			err = shutdownErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+	for atomic.LoadInt64(&j.lifecycle.inflight) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+func (l *lifecycle) begin() {
+	atomic.AddInt64(&l.inflight, 1)
+}
+
+func (l *lifecycle) end() {
+	atomic.AddInt64(&l.inflight, -1)
+}
@@ -0,0 +1,94 @@
+package jago
+
+import (
+	stdcontext "context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJagoGracefulShutdown(t *testing.T) {
+	g := New()
+	started := make(chan struct{})
+	g.Get("/slow", func(c Context) error {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		return c.String(http.StatusOK, "done")
+	})
+
+	errc := make(chan error, 1)
+	go func() { errc <- g.Start("127.0.0.1:0") }()
+	for g.ListenerAddr() == nil {
+		time.Sleep(time.Millisecond)
+	}
+
+	type result struct {
+		body string
+		err  error
+	}
+	resc := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + g.ListenerAddr().String() + "/slow")
+		if err != nil {
+			resc <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		resc <- result{body: string(b), err: err}
+	}()
+
+	<-started
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, g.Shutdown(ctx))
+	assert.Equal(t, http.ErrServerClosed, <-errc)
+
+	res := <-resc
+	assert.NoError(t, res.err)
+	assert.Equal(t, "done", res.body)
+}
+
+func TestJagoShutdownDeadline(t *testing.T) {
+	g := New()
+	g.lifecycle.begin()
+	defer g.lifecycle.end()
+
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, stdcontext.DeadlineExceeded, g.Shutdown(ctx))
+}
+
+func TestJagoStartAfterShutdown(t *testing.T) {
+	g := New()
+	assert.NoError(t, g.Shutdown(stdcontext.Background()))
+	assert.Equal(t, http.ErrServerClosed, g.Start("127.0.0.1:0"))
+	assert.Nil(t, g.ListenerAddr())
+}
+
+func TestJagoShutdownAllServers(t *testing.T) {
+	g := New()
+	g.Get("/", func(c Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	errc := make(chan error, 2)
+	go func() { errc <- g.Start("127.0.0.1:0") }()
+	go func() { errc <- g.StartServer(&http.Server{Addr: "127.0.0.1:0"}) }()
+	for len(g.ListenerAddrs()) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	addrs := g.ListenerAddrs()
+	assert.Equal(t, addrs[0], g.ListenerAddr())
+
+	assert.NoError(t, g.Shutdown(stdcontext.Background()))
+	assert.Equal(t, http.ErrServerClosed, <-errc)
+	assert.Equal(t, http.ErrServerClosed, <-errc)
+	for _, addr := range addrs {
+		_, err := http.Get("http://" + addr.String() + "/")
+		assert.Error(t, err)
+	}
+}
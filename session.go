@@ -0,0 +1,281 @@
+package jago
+
+import (
+	"encoding/gob"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	SessionConfig struct {
+		Skipper Skipper
+
+		// Store loads and saves sessions. It is required.
+		Store SessionStore
+
+		// Cookie settings. CookieName defaults to "session", CookiePath to
+		// "/", MaxAge to 24 hours and CookieSameSite to Lax. The cookie is
+		// always HttpOnly.
+		CookieName     string
+		CookieDomain   string
+		CookiePath     string
+		MaxAge         time.Duration
+		CookieSecure   bool
+		CookieSameSite http.SameSite
+	}
+
+	// SessionStore keeps session data. Cookie stores put the data in the
+	// cookie itself, server-side stores only put the session ID there.
+	SessionStore interface {
+		// Load returns the session held by a cookie value, or nil when the
+		// value is unknown, expired or tampered with.
+		Load(value string) (*Session, error)
+		// Save persists s and returns the cookie value to send.
+		Save(s *Session) (string, error)
+		// Delete forgets the session with the given ID.
+		Delete(id string) error
+	}
+
+	// Session holds per client values across requests. It is safe for
+	// concurrent use.
+	Session struct {
+		ID    string
+		IsNew bool
+
+		mu         sync.RWMutex
+		values     map[string]interface{}
+		previousID string
+		changed    bool
+		destroyed  bool
+	}
+)
+
+// SessionKey is the Context key holding the *Session of the request.
+const SessionKey = "session"
+
+const sessionFlashKey = "_flash"
+
+var DefaultSessionConfig = SessionConfig{
+	Skipper:        DefaultSkipper,
+	CookieName:     "session",
+	CookiePath:     "/",
+	MaxAge:         24 * time.Hour,
+	CookieSameSite: http.SameSiteLaxMode,
+}
+
+func init() {
+	// flashes are kept as a []interface{} value
+	gob.Register([]interface{}(nil))
+}
+
+// Sessions loads the session of each request from store and makes it
+// available through Context.Session. A modified session is saved just before
+// the response is committed.
+func Sessions(store SessionStore) HandlerFunc {
+	config := DefaultSessionConfig
+	config.Store = store
+	return SessionsWithConfig(config)
+}
+
+func SessionsWithConfig(config SessionConfig) HandlerFunc {
+	if config.Store == nil {
+		panic("jago: session middleware requires a store")
+	}
+	if config.Skipper == nil {
+		config.Skipper = DefaultSessionConfig.Skipper
+	}
+	if config.CookieName == "" {
+		config.CookieName = DefaultSessionConfig.CookieName
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = DefaultSessionConfig.CookiePath
+	}
+	if config.MaxAge == 0 {
+		config.MaxAge = DefaultSessionConfig.MaxAge
+	}
+	if config.CookieSameSite == 0 {
+		config.CookieSameSite = DefaultSessionConfig.CookieSameSite
+	}
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+
+		var s *Session
+		if cookie, err := c.Cookie(config.CookieName); err == nil && cookie.Value != "" {
+			if s, err = config.Store.Load(cookie.Value); err != nil {
+				return err
+			}
+		}
+		if s == nil {
+			s = NewSession(randomToken(32), nil)
+			s.IsNew = true
+		}
+		c.Set(SessionKey, s)
+
+		saved := false
+		save := func() error {
+			if saved {
+				return nil
+			}
+			saved = true
+			return config.save(c, s)
+		}
+		c.Response().Before(func() {
+			if err := save(); err != nil {
+				log.Println(err)
+			}
+		})
+
+		err := c.Next()
+		if !c.Response().Committed {
+			if serr := save(); serr != nil && err == nil {
+				err = serr
+			}
+		}
+		return err
+	}
+}
+
+func (config *SessionConfig) save(c Context, s *Session) error {
+	s.mu.Lock()
+	previousID, destroyed, changed := s.previousID, s.destroyed, s.changed
+	s.previousID, s.changed = "", false
+	s.mu.Unlock()
+
+	cookie := &http.Cookie{
+		Name:     config.CookieName,
+		Path:     config.CookiePath,
+		Domain:   config.CookieDomain,
+		Secure:   config.CookieSecure,
+		HttpOnly: true,
+		SameSite: config.CookieSameSite,
+	}
+	if previousID != "" {
+		if err := config.Store.Delete(previousID); err != nil {
+			return fmt.Errorf("session: delete %s: %w", previousID, err)
+		}
+	}
+	if destroyed {
+		if !s.IsNew {
+			if err := config.Store.Delete(s.ID); err != nil {
+				return fmt.Errorf("session: delete %s: %w", s.ID, err)
+			}
+		}
+		cookie.MaxAge = -1
+		c.Response().SetCookie(cookie)
+		return nil
+	}
+	if !changed {
+		return nil
+	}
+
+	value, err := config.Store.Save(s)
+	if err != nil {
+		return fmt.Errorf("session: save: %w", err)
+	}
+	cookie.Value = value
+	cookie.MaxAge = int(config.MaxAge / time.Second)
+	cookie.Expires = time.Now().Add(config.MaxAge)
+	c.Response().SetCookie(cookie)
+	return nil
+}
+
+// Session returns the session loaded by the Sessions middleware. It panics
+// when the middleware is not registered.
+func (c *context) Session() *Session {
+	s, ok := Value[*Session](c, SessionKey)
+	if !ok {
+		panic("jago: session middleware not registered")
+	}
+	return s
+}
+
+// NewSession creates a session with a copy of values. Stores use it to
+// rebuild loaded sessions.
+func NewSession(id string, values map[string]interface{}) *Session {
+	s := &Session{ID: id, values: make(map[string]interface{}, len(values))}
+	for k, v := range values {
+		s.values[k] = v
+	}
+	return s
+}
+
+func (s *Session) Get(key string) interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.values[key]
+}
+
+func (s *Session) Set(key string, val interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = val
+	s.changed = true
+}
+
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.changed = true
+	}
+}
+
+// Values returns a copy of the session values.
+func (s *Session) Values() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	values := make(map[string]interface{}, len(s.values))
+	for k, v := range s.values {
+		values[k] = v
+	}
+	return values
+}
+
+// Flash adds a message that is kept until read by Flashes, typically on the
+// next request.
+func (s *Session) Flash(val interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flashes, _ := s.values[sessionFlashKey].([]interface{})
+	s.values[sessionFlashKey] = append(flashes, val)
+	s.changed = true
+}
+
+// Flashes returns and removes the pending flash messages.
+func (s *Session) Flashes() []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flashes, ok := s.values[sessionFlashKey].([]interface{})
+	if ok {
+		delete(s.values, sessionFlashKey)
+		s.changed = true
+	}
+	return flashes
+}
+
+// RegenerateID gives the session a new ID and drops the old one from the
+// store. Call it on login to prevent session fixation.
+func (s *Session) RegenerateID() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.previousID == "" && !s.IsNew {
+		s.previousID = s.ID
+	}
+	s.ID = randomToken(32)
+	s.changed = true
+}
+
+// Destroy removes the session from the store and expires the cookie.
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = make(map[string]interface{})
+	s.destroyed = true
+}
@@ -0,0 +1,217 @@
+package jago
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// CookieSessionStore keeps the whole session in the cookie, signed with
+	// HMAC-SHA256 or encrypted with AES-GCM. Values are encoded with
+	// encoding/gob, so custom types must be registered with gob.Register.
+	CookieSessionStore struct {
+		// MaxAge rejects cookies older than this. Defaults to 24 hours.
+		MaxAge time.Duration
+
+		hashKey []byte
+		aead    cipher.AEAD
+	}
+
+	// MemorySessionStore keeps sessions in memory and drops the ones idle
+	// for longer than its TTL. Sessions are lost on restart and are not
+	// shared between instances.
+	MemorySessionStore struct {
+		mu          sync.Mutex
+		sessions    map[string]*memorySession
+		ttl         time.Duration
+		lastCleanup time.Time
+		now         func() time.Time
+	}
+
+	memorySession struct {
+		values  map[string]interface{}
+		expires time.Time
+	}
+
+	cookieSession struct {
+		ID      string
+		Values  map[string]interface{}
+		Created int64
+	}
+)
+
+// maxCookieSize is the size browsers are guaranteed to keep.
+const maxCookieSize = 4096
+
+var errSessionTooLarge = errors.New("session: cookie exceeds 4096 bytes")
+
+// NewCookieSessionStore signs sessions with secret. The values are readable
+// by the client but cannot be changed.
+func NewCookieSessionStore(secret []byte) *CookieSessionStore {
+	if len(secret) == 0 {
+		panic("jago: cookie session store requires a secret")
+	}
+	return &CookieSessionStore{MaxAge: 24 * time.Hour, hashKey: secret}
+}
+
+// NewEncryptedCookieSessionStore encrypts sessions with AES-GCM. The key
+// must be 16, 24 or 32 bytes long.
+func NewEncryptedCookieSessionStore(key []byte) *CookieSessionStore {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic("jago: invalid session encryption key: " + err.Error())
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic("jago: invalid session encryption key: " + err.Error())
+	}
+	return &CookieSessionStore{MaxAge: 24 * time.Hour, aead: aead}
+}
+
+func (s *CookieSessionStore) Load(value string) (*Session, error) {
+	b, ok := s.open(value)
+	if !ok {
+		return nil, nil
+	}
+	var cs cookieSession
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&cs); err != nil {
+		return nil, nil
+	}
+	if time.Since(time.Unix(cs.Created, 0)) > s.MaxAge {
+		return nil, nil
+	}
+	return NewSession(cs.ID, cs.Values), nil
+}
+
+func (s *CookieSessionStore) Save(session *Session) (string, error) {
+	var buf bytes.Buffer
+	cs := cookieSession{ID: session.ID, Values: session.Values(), Created: time.Now().Unix()}
+	if err := gob.NewEncoder(&buf).Encode(&cs); err != nil {
+		return "", err
+	}
+	value, err := s.seal(buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+	if len(value) > maxCookieSize {
+		return "", errSessionTooLarge
+	}
+	return value, nil
+}
+
+// Delete does nothing: the session disappears with its cookie.
+func (s *CookieSessionStore) Delete(id string) error {
+	return nil
+}
+
+func (s *CookieSessionStore) seal(b []byte) (string, error) {
+	enc := base64.RawURLEncoding
+	if s.aead == nil {
+		return enc.EncodeToString(b) + "." + enc.EncodeToString(s.sign(b)), nil
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("session: nonce: %w", err)
+	}
+	return enc.EncodeToString(s.aead.Seal(nonce, nonce, b, nil)), nil
+}
+
+func (s *CookieSessionStore) open(value string) ([]byte, bool) {
+	enc := base64.RawURLEncoding
+	if s.aead == nil {
+		i := strings.IndexByte(value, '.')
+		if i < 0 {
+			return nil, false
+		}
+		b, err := enc.DecodeString(value[:i])
+		if err != nil {
+			return nil, false
+		}
+		sig, err := enc.DecodeString(value[i+1:])
+		if err != nil || !hmac.Equal(sig, s.sign(b)) {
+			return nil, false
+		}
+		return b, true
+	}
+	sealed, err := enc.DecodeString(value)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return nil, false
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	b, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	return b, err == nil
+}
+
+func (s *CookieSessionStore) sign(b []byte) []byte {
+	mac := hmac.New(sha256.New, s.hashKey)
+	mac.Write(b)
+	return mac.Sum(nil)
+}
+
+// NewMemorySessionStore keeps sessions for ttl after their last save.
+func NewMemorySessionStore(ttl time.Duration) *MemorySessionStore {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &MemorySessionStore{
+		sessions: make(map[string]*memorySession),
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+func (s *MemorySessionStore) Load(id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ms, ok := s.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	if !s.now().Before(ms.expires) {
+		delete(s.sessions, id)
+		return nil, nil
+	}
+	return NewSession(id, ms.values), nil
+}
+
+func (s *MemorySessionStore) Save(session *Session) (string, error) {
+	values := session.Values()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.cleanup(now)
+	s.sessions[session.ID] = &memorySession{values: values, expires: now.Add(s.ttl)}
+	return session.ID, nil
+}
+
+func (s *MemorySessionStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+// cleanup drops expired sessions at most once per TTL. The caller holds
+// s.mu.
+func (s *MemorySessionStore) cleanup(now time.Time) {
+	if now.Sub(s.lastCleanup) < s.ttl {
+		return
+	}
+	s.lastCleanup = now
+	for id, ms := range s.sessions {
+		if !now.Before(ms.expires) {
+			delete(s.sessions, id)
+		}
+	}
+}
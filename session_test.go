@@ -0,0 +1,150 @@
+package jago
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sessionApp(store SessionStore) *Jago {
+	g := New()
+	g.Use(Sessions(store))
+	g.Post("/login", func(c Context) error {
+		s := c.Session()
+		s.RegenerateID()
+		s.Set("user", "jago")
+		s.Flash("welcome")
+		return c.NoContent(http.StatusNoContent)
+	})
+	g.Get("/me", func(c Context) error {
+		s := c.Session()
+		user, _ := s.Get("user").(string)
+		flashes := s.Flashes()
+		if len(flashes) > 0 {
+			user += " " + flashes[0].(string)
+		}
+		return c.String(http.StatusOK, user)
+	})
+	g.Post("/logout", func(c Context) error {
+		c.Session().Destroy()
+		return nil
+	})
+	return g
+}
+
+func sessionRequest(g *Jago, method, target string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	return rec
+}
+
+func sessionCookie(rec *httptest.ResponseRecorder) *http.Cookie {
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == "session" {
+			return c
+		}
+	}
+	return nil
+}
+
+func testSessionFlow(t *testing.T, store SessionStore) {
+	g := sessionApp(store)
+
+	rec := sessionRequest(g, http.MethodGet, "/me", nil)
+	assert.Equal(t, "", rec.Body.String())
+	assert.Nil(t, sessionCookie(rec), "untouched sessions are not saved")
+
+	rec = sessionRequest(g, http.MethodPost, "/login", nil)
+	cookie := sessionCookie(rec)
+	assert.NotNil(t, cookie)
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, 86400, cookie.MaxAge)
+
+	rec = sessionRequest(g, http.MethodGet, "/me", cookie)
+	assert.Equal(t, "jago welcome", rec.Body.String())
+	// reading the flash updates the session
+	next := sessionCookie(rec)
+	assert.NotNil(t, next)
+
+	rec = sessionRequest(g, http.MethodGet, "/me", next)
+	assert.Equal(t, "jago", rec.Body.String())
+
+	rec = sessionRequest(g, http.MethodPost, "/logout", next)
+	assert.Equal(t, -1, sessionCookie(rec).MaxAge)
+}
+
+func TestSessionMemoryStore(t *testing.T) {
+	store := NewMemorySessionStore(time.Hour)
+	testSessionFlow(t, store)
+	assert.Empty(t, store.sessions, "logout deletes the session")
+
+	g := sessionApp(store)
+	first := sessionCookie(sessionRequest(g, http.MethodPost, "/login", nil))
+	second := sessionCookie(sessionRequest(g, http.MethodPost, "/login", first))
+	assert.NotEqual(t, first.Value, second.Value, "login rotates the session id")
+	assert.Equal(t, "", sessionRequest(g, http.MethodGet, "/me", first).Body.String())
+	assert.Equal(t, "jago welcome", sessionRequest(g, http.MethodGet, "/me", second).Body.String())
+}
+
+func TestSessionMemoryStoreExpiry(t *testing.T) {
+	now := time.Now()
+	store := NewMemorySessionStore(time.Minute)
+	store.now = func() time.Time { return now }
+	g := sessionApp(store)
+
+	cookie := sessionCookie(sessionRequest(g, http.MethodPost, "/login", nil))
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, "", sessionRequest(g, http.MethodGet, "/me", cookie).Body.String())
+}
+
+func TestSessionCookieStore(t *testing.T) {
+	store := NewCookieSessionStore([]byte("secret"))
+	testSessionFlow(t, store)
+
+	g := sessionApp(store)
+	cookie := sessionCookie(sessionRequest(g, http.MethodPost, "/login", nil))
+	tampered := *cookie
+	tampered.Value = strings.Replace(cookie.Value, ".", "x.", 1)
+	assert.Equal(t, "", sessionRequest(g, http.MethodGet, "/me", &tampered).Body.String())
+
+	store.MaxAge = -time.Second
+	assert.Equal(t, "", sessionRequest(g, http.MethodGet, "/me", cookie).Body.String())
+}
+
+func TestSessionEncryptedCookieStore(t *testing.T) {
+	store := NewEncryptedCookieSessionStore([]byte("0123456789abcdef"))
+	testSessionFlow(t, store)
+
+	g := sessionApp(store)
+	cookie := sessionCookie(sessionRequest(g, http.MethodPost, "/login", nil))
+	other := NewEncryptedCookieSessionStore([]byte("fedcba9876543210"))
+	assert.Equal(t, "", sessionRequest(sessionApp(other), http.MethodGet, "/me", cookie).Body.String())
+
+	assert.Panics(t, func() { NewEncryptedCookieSessionStore([]byte("short")) })
+}
+
+func TestSessionSavedBeforeCommit(t *testing.T) {
+	g := New()
+	g.Use(Sessions(NewMemorySessionStore(time.Hour)))
+	g.Get("/stream", func(c Context) error {
+		c.Session().Set("seen", true)
+		c.Response().WriteHeader(http.StatusOK)
+		c.Response().Flush()
+		c.Session().Set("late", true)
+		return nil
+	})
+
+	rec := sessionRequest(g, http.MethodGet, "/stream", nil)
+	assert.Len(t, rec.Result().Cookies(), 1)
+	assert.Panics(t, func() {
+		New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder()).Session()
+	})
+}
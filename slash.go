@@ -0,0 +1,82 @@
+package jago
+
+import (
+	"net/http"
+	"strings"
+)
+
+type (
+	TrailingSlashConfig struct {
+		Skipper Skipper
+		// RedirectCode is the status used to redirect to the canonical URL.
+		// When zero the request path is rewritten in place instead.
+		RedirectCode int
+	}
+)
+
+var DefaultTrailingSlashConfig = TrailingSlashConfig{
+	Skipper:      DefaultSkipper,
+	RedirectCode: http.StatusMovedPermanently,
+}
+
+// AddTrailingSlash redirects requests without a trailing slash to the same
+// URL with one. Register it with Jago.Pre.
+func AddTrailingSlash() HandlerFunc {
+	return AddTrailingSlashWithConfig(DefaultTrailingSlashConfig)
+}
+
+func AddTrailingSlashWithConfig(config TrailingSlashConfig) HandlerFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultTrailingSlashConfig.Skipper
+	}
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+		path := c.Request().URL.Path
+		if strings.HasSuffix(path, "/") {
+			return c.Next()
+		}
+		return trailingSlash(c, config, path+"/")
+	}
+}
+
+// RemoveTrailingSlash redirects requests with a trailing slash to the same
+// URL without it. Register it with Jago.Pre.
+func RemoveTrailingSlash() HandlerFunc {
+	return RemoveTrailingSlashWithConfig(DefaultTrailingSlashConfig)
+}
+
+func RemoveTrailingSlashWithConfig(config TrailingSlashConfig) HandlerFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultTrailingSlashConfig.Skipper
+	}
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+		path := c.Request().URL.Path
+		if path == "/" || !strings.HasSuffix(path, "/") {
+			return c.Next()
+		}
+		return trailingSlash(c, config, strings.TrimRight(path, "/"))
+	}
+}
+
+func trailingSlash(c Context, config TrailingSlashConfig, path string) error {
+	req := c.Request()
+	if config.RedirectCode == 0 {
+		req.URL.Path = path
+		req.URL.RawPath = ""
+		req.RequestURI = req.URL.RequestURI()
+		return c.Next()
+	}
+
+	// avoid open redirects through paths like "//evil.com"
+	path = "/" + strings.TrimLeft(path, "/")
+	u := *req.URL
+	u.Scheme, u.Host, u.Path, u.RawPath = "", "", path, ""
+	return c.Redirect(config.RedirectCode, u.RequestURI())
+}
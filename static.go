@@ -0,0 +1,243 @@
+package jago
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type (
+	StaticConfig struct {
+		Skipper Skipper
+
+		// Root is the directory to serve from the OS file system. It is
+		// ignored when Filesystem is set.
+		Root string
+
+		// Filesystem to serve from, such as an embed.FS or os.DirFS.
+		Filesystem fs.FS
+
+		// Index is served for directories. Defaults to "index.html".
+		Index string
+
+		// Browse lists directories without an index file.
+		Browse bool
+	}
+)
+
+var DefaultStaticConfig = StaticConfig{
+	Skipper: DefaultSkipper,
+	Index:   "index.html",
+}
+
+// StaticWithConfig returns a handler serving files from config.Root or
+// config.Filesystem. Register it on a wildcard route, such as
+// "/assets/*": the file name is the wildcard param, or the request path
+// when the route has none. Paths never escape the root, and directories
+// are redirected to their path with a trailing slash.
+func StaticWithConfig(config StaticConfig) HandlerFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultStaticConfig.Skipper
+	}
+	if config.Index == "" {
+		config.Index = DefaultStaticConfig.Index
+	}
+	if config.Filesystem == nil {
+		if config.Root == "" {
+			panic("jago: static middleware requires a root or a filesystem")
+		}
+		config.Filesystem = os.DirFS(config.Root)
+	}
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+
+		name := c.Param("*")
+		if name == "" && !strings.HasSuffix(c.Path(), "*") {
+			name = c.Request().URL.Path
+		}
+		return serveFS(c, config.Filesystem, name, config.Index, config.Browse)
+	}
+}
+
+// Static serves the files under root for paths starting with prefix.
+func (j *Jago) Static(prefix, root string) {
+	addStatic(j.Add, staticPath(prefix), StaticWithConfig(StaticConfig{Root: root}))
+}
+
+// StaticFS serves the files of fsys for paths starting with prefix.
+func (j *Jago) StaticFS(prefix string, fsys fs.FS) {
+	addStatic(j.Add, staticPath(prefix), StaticWithConfig(StaticConfig{Filesystem: fsys}))
+}
+
+// File serves a single file at path.
+func (j *Jago) File(path, file string) {
+	addStatic(j.Add, path, fileHandler(file))
+}
+
+func (g *Group) Static(prefix, root string) {
+	addStatic(g.Add, staticPath(prefix), StaticWithConfig(StaticConfig{Root: root}))
+}
+
+func (g *Group) StaticFS(prefix string, fsys fs.FS) {
+	addStatic(g.Add, staticPath(prefix), StaticWithConfig(StaticConfig{Filesystem: fsys}))
+}
+
+func (g *Group) File(path, file string) {
+	addStatic(g.Add, path, fileHandler(file))
+}
+
+// addStatic registers h for GET and HEAD, which http.ServeContent answers
+// without a body.
+func addStatic(add func(method, path string, handlers ...HandlerFunc), path string, h HandlerFunc) {
+	add(http.MethodGet, path, h)
+	add(http.MethodHead, path, h)
+}
+
+func fileHandler(file string) HandlerFunc {
+	return func(c Context) error {
+		return c.File(file)
+	}
+}
+
+func staticPath(prefix string) string {
+	return strings.TrimSuffix(prefix, "/") + "/*"
+}
+
+// File sends a file from the OS file system, or the index.html of a
+// directory. Range and conditional requests are supported.
+func (c *context) File(file string) error {
+	dir, name := filepath.Split(file)
+	if dir == "" {
+		dir = "."
+	}
+	return serveFS(c, os.DirFS(dir), name, DefaultStaticConfig.Index, false)
+}
+
+// FileFS sends a file from fsys.
+func (c *context) FileFS(name string, fsys fs.FS) error {
+	return serveFS(c, fsys, name, DefaultStaticConfig.Index, false)
+}
+
+// Attachment sends a file the browser should download as name.
+func (c *context) Attachment(file, name string) error {
+	return c.contentDisposition(file, name, "attachment")
+}
+
+// Inline sends a file the browser should display, suggesting name when it
+// is saved.
+func (c *context) Inline(file, name string) error {
+	return c.contentDisposition(file, name, "inline")
+}
+
+func (c *context) contentDisposition(file, name, disposition string) error {
+	c.response.Header().Set(HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": name}))
+	return c.File(file)
+}
+
+// serveFS sends name from fsys. name is cleaned and made relative to the
+// root so it cannot reach outside of fsys.
+func serveFS(c Context, fsys fs.FS, name, index string, browse bool) error {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		return ErrNotFound
+	}
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return fileError(err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return fileError(err)
+	}
+
+	if fi.IsDir() {
+		if p := c.Request().URL.Path; !strings.HasSuffix(p, "/") {
+			// relative links in the directory need the trailing slash;
+			// collapse leading slashes so the target stays on this host
+			target := "/" + strings.TrimLeft(p, "/") + "/"
+			if q := c.Request().URL.RawQuery; q != "" {
+				target += "?" + q
+			}
+			return c.Redirect(http.StatusMovedPermanently, target)
+		}
+		indexFile, err := fsys.Open(path.Join(name, index))
+		if err != nil {
+			if browse && errors.Is(err, fs.ErrNotExist) {
+				return listDir(c, fsys, name)
+			}
+			return fileError(err)
+		}
+		defer indexFile.Close()
+		if fi, err = indexFile.Stat(); err != nil {
+			return fileError(err)
+		}
+		if fi.IsDir() {
+			return ErrNotFound
+		}
+		f = indexFile
+	}
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		content = bytes.NewReader(b)
+	}
+	http.ServeContent(c.Response(), c.Request(), fi.Name(), fi.ModTime(), content)
+	return nil
+}
+
+func listDir(c Context, fsys fs.FS, name string) error {
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		return fileError(err)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	base := strings.TrimSuffix(c.Request().URL.Path, "/") + "/"
+	var b strings.Builder
+	b.WriteString("<!doctype html>\n<meta name=\"viewport\" content=\"width=device-width\">\n<pre>\n")
+	for _, e := range entries {
+		n := e.Name()
+		if e.IsDir() {
+			n += "/"
+		}
+		href := (&url.URL{Path: base + n}).String()
+		fmt.Fprintf(&b, "<a href=\"%s\">%s</a>\n", html.EscapeString(href), html.EscapeString(n))
+	}
+	b.WriteString("</pre>\n")
+	return c.HTML(http.StatusOK, b.String())
+}
+
+func fileError(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return ErrNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return ErrForbidden
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+package jago
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func staticFS() fstest.MapFS {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return fstest.MapFS{
+		"index.html":        {Data: []byte("<h1>home</h1>"), ModTime: modTime},
+		"css/site.css":      {Data: []byte("body{}"), ModTime: modTime},
+		"docs/index.html":   {Data: []byte("docs"), ModTime: modTime},
+		"files/a.txt":       {Data: []byte("0123456789"), ModTime: modTime},
+		"files/<b>.txt":     {Data: []byte("b"), ModTime: modTime},
+		"files/nested/c.js": {Data: []byte("c"), ModTime: modTime},
+	}
+}
+
+func TestStaticFS(t *testing.T) {
+	g := New()
+	g.StaticFS("/assets", staticFS())
+
+	rec := serve(g, http.MethodGet, "/assets/css/site.css")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "body{}", rec.Body.String())
+	assert.Equal(t, "text/css; charset=utf-8", rec.Header().Get(HeaderContentType))
+	assert.Equal(t, "Tue, 02 Jan 2024 03:04:05 GMT", rec.Header().Get(HeaderLastModified))
+
+	rec = serve(g, http.MethodGet, "/assets/")
+	assert.Equal(t, "<h1>home</h1>", rec.Body.String())
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get(HeaderContentType))
+
+	rec = serve(g, http.MethodGet, "/assets/docs?v=1")
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "/assets/docs/?v=1", rec.Header().Get(HeaderLocation))
+	assert.Equal(t, "docs", serve(g, http.MethodGet, "/assets/docs/").Body.String())
+
+	assert.Equal(t, http.StatusNotFound, serve(g, http.MethodGet, "/assets/missing.css").Code)
+	assert.Equal(t, http.StatusNotFound, serve(g, http.MethodGet, "/assets/files/").Code, "listing is opt-in")
+	assert.Equal(t, http.StatusNotFound, serve(g, http.MethodGet, "/assets/%2e%2e/static.go").Code)
+
+	// HEAD works without AutoHead
+	rec = serve(g, http.MethodHead, "/assets/css/site.css")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "6", rec.Header().Get(HeaderContentLength))
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestStaticConditionalAndRange(t *testing.T) {
+	g := New()
+	g.StaticFS("/", staticFS())
+
+	req := httptest.NewRequest(http.MethodGet, "/files/a.txt", nil)
+	req.Header.Set(HeaderIfModifiedSince, "Tue, 02 Jan 2024 03:04:05 GMT")
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/files/a.txt", nil)
+	req.Header.Set("Range", "bytes=2-4")
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "234", rec.Body.String())
+	assert.Equal(t, "bytes 2-4/10", rec.Header().Get("Content-Range"))
+
+	assert.Equal(t, "<h1>home</h1>", serve(g, http.MethodGet, "/").Body.String())
+}
+
+func TestStaticBrowse(t *testing.T) {
+	g := New()
+	g.Get("/files/*", StaticWithConfig(StaticConfig{Filesystem: staticFS(), Browse: true}))
+	g.Get("/raw/*path", StaticWithConfig(StaticConfig{Filesystem: staticFS()}))
+
+	rec := serve(g, http.MethodGet, "/files/files/")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `<a href="/files/files/a.txt">a.txt</a>`)
+	assert.Contains(t, rec.Body.String(), `<a href="/files/files/nested/">nested/</a>`)
+	assert.Contains(t, rec.Body.String(), `&lt;b&gt;.txt`)
+
+	assert.Equal(t, "c", serve(g, http.MethodGet, "/raw/files/nested/c.js").Body.String())
+}
+
+func TestStaticOS(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "report.pdf"), []byte("%PDF"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "logo.png"), []byte("png"), 0o600))
+
+	g := New()
+	g.AutoHead = true
+	g.Static("/public", dir)
+	g.File("/logo", filepath.Join(dir, "logo.png"))
+	api := g.Group("/api")
+	api.Static("/files", dir)
+	g.Get("/download", func(c Context) error {
+		return c.Attachment(filepath.Join(dir, "report.pdf"), "année 2024.pdf")
+	})
+	g.Get("/view", func(c Context) error {
+		return c.Inline(filepath.Join(dir, "report.pdf"), "report.pdf")
+	})
+
+	rec := serve(g, http.MethodGet, "/public/report.pdf")
+	assert.Equal(t, "%PDF", rec.Body.String())
+	assert.Equal(t, "application/pdf", rec.Header().Get(HeaderContentType))
+	assert.Equal(t, "image/png", serve(g, http.MethodGet, "/logo").Header().Get(HeaderContentType))
+	assert.Equal(t, "%PDF", serve(g, http.MethodGet, "/api/files/report.pdf").Body.String())
+	assert.Equal(t, http.StatusNotFound, serve(g, http.MethodGet, "/public/../jago.go").Code)
+
+	rec = serve(g, http.MethodGet, "/download")
+	assert.Equal(t, "attachment; filename*=utf-8''ann%C3%A9e%202024.pdf", rec.Header().Get(HeaderContentDisposition))
+	rec = serve(g, http.MethodGet, "/view")
+	assert.Equal(t, `inline; filename=report.pdf`, rec.Header().Get(HeaderContentDisposition))
+
+	rec = serve(g, http.MethodHead, "/public/report.pdf")
+	assert.Equal(t, "4", rec.Header().Get(HeaderContentLength))
+	assert.Empty(t, rec.Body.String())
+}
@@ -0,0 +1,195 @@
+package jago
+
+import (
+	"bytes"
+	stdcontext "context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	TimeoutConfig struct {
+		Skipper Skipper
+
+		// Timeout is the time the rest of the chain is allowed to run.
+		Timeout time.Duration
+
+		// Error is returned to HTTPErrorHandler when the chain overruns.
+		// Defaults to ErrServiceUnavailable.
+		Error error
+	}
+
+	// timeoutWriter buffers the response of a chain running under Timeout
+	// and rejects writes once the deadline passed.
+	timeoutWriter struct {
+		mu       sync.Mutex
+		header   http.Header
+		buf      bytes.Buffer
+		code     int
+		timedOut bool
+	}
+)
+
+var DefaultTimeoutConfig = TimeoutConfig{
+	Skipper: DefaultSkipper,
+	Timeout: 30 * time.Second,
+	Error:   ErrServiceUnavailable,
+}
+
+// Timeout runs the rest of the chain with a deadline on the request context.
+// The response is buffered and only sent if the chain finishes in time;
+// otherwise config.Error goes to HTTPErrorHandler and later writes fail
+// with http.ErrHandlerTimeout. Handlers should watch Request().Context() to
+// stop early. Streaming with Flush is not supported under Timeout.
+//
+// The rest of the chain runs on a copy of the Context. Values it stores with
+// Set are copied back when it finishes in time, but a request replaced with
+// SetRequest, e.g. by Rewrite, is not seen by the middlewares registered
+// before Timeout. A panic raised after the deadline can no longer reach
+// Recover and is logged with its stack trace instead.
+func Timeout(timeout time.Duration) HandlerFunc {
+	config := DefaultTimeoutConfig
+	config.Timeout = timeout
+	return TimeoutWithConfig(config)
+}
+
+func TimeoutWithConfig(config TimeoutConfig) HandlerFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultTimeoutConfig.Skipper
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeoutConfig.Timeout
+	}
+	if config.Error == nil {
+		config.Error = DefaultTimeoutConfig.Error
+	}
+
+	return func(c Context) error {
+		if config.Skipper(c) {
+			return c.Next()
+		}
+
+		ctx, cancel := stdcontext.WithTimeout(c.Request().Context(), config.Timeout)
+		defer cancel()
+
+		tw := &timeoutWriter{header: c.Response().Header().Clone()}
+		tc := c.(*context).fork(c.Request().WithContext(ctx), tw)
+
+		done := make(chan error, 1)
+		panicked := make(chan *PanicError, 1)
+		go func() {
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				// capture the stack here, it is gone once the panic is
+				// raised again on the handler goroutine
+				pe := newPanicError(p)
+				tw.mu.Lock()
+				late := tw.timedOut
+				tw.mu.Unlock()
+				if !late {
+					panicked <- pe
+					return
+				}
+				log.Printf("[PANIC AFTER TIMEOUT] %v %s\n", pe.Value, pe.Stack)
+			}()
+			done <- tc.Next()
+		}()
+
+		select {
+		case pe := <-panicked:
+			panic(forwardPanic(pe))
+		case err := <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			c.(*context).join(tc)
+			return tw.replay(c.Response(), err)
+		case <-ctx.Done():
+			tw.mu.Lock()
+			tw.timedOut = true
+			tw.mu.Unlock()
+			select {
+			case pe := <-panicked:
+				// the chain panicked just before the deadline
+				panic(forwardPanic(pe))
+			default:
+			}
+			if ctx.Err() == stdcontext.DeadlineExceeded {
+				return config.Error
+			}
+			// the client went away
+			return ctx.Err()
+		}
+	}
+}
+
+// forwardPanic returns the value to raise again on the handler goroutine:
+// pe, so Recover reports the original stack, or http.ErrAbortHandler as is
+// so net/http still aborts the response quietly.
+func forwardPanic(pe *PanicError) interface{} {
+	if pe.Value == http.ErrAbortHandler {
+		return pe.Value
+	}
+	return pe
+}
+
+// join copies back the values a forked chain stored.
+func (c *context) join(fc *context) {
+	fc.storeMu.RLock()
+	defer fc.storeMu.RUnlock()
+	for k, v := range fc.store {
+		c.Set(k, v)
+	}
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.code != 0 {
+		return
+	}
+	tw.code = code
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	return tw.buf.Write(b)
+}
+
+// Flush is a no-op: the body is sent once the chain completes.
+func (tw *timeoutWriter) Flush() {}
+
+// replay sends the buffered response through res. The caller holds tw.mu.
+func (tw *timeoutWriter) replay(res *Response, err error) error {
+	dst := res.Header()
+	for k := range dst {
+		delete(dst, k)
+	}
+	for k, v := range tw.header {
+		dst[k] = v
+	}
+	if tw.code == 0 {
+		return err
+	}
+	res.WriteHeader(tw.code)
+	if _, writeErr := res.Write(tw.buf.Bytes()); writeErr != nil && err == nil {
+		err = fmt.Errorf("timeout: replay response: %w", writeErr)
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package jago
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	lateWrite := make(chan error, 1)
+	g := New()
+	g.Use(RequestID())
+	g.Use(Timeout(50 * time.Millisecond))
+	g.Get("/fast/:id", func(c Context) error {
+		c.Set("seen", c.Param("id"))
+		return c.String(http.StatusCreated, "fast "+c.Param("id"))
+	})
+	g.Get("/slow", func(c Context) error {
+		<-c.Request().Context().Done()
+		time.Sleep(10 * time.Millisecond)
+		_, err := c.Response().Write([]byte("late"))
+		lateWrite <- err
+		return err
+	})
+
+	rec := serve(g, http.MethodGet, "/fast/1")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "fast 1", rec.Body.String())
+	assert.NotEmpty(t, rec.Header().Get(HeaderXRequestID))
+
+	rec = serve(g, http.MethodGet, "/slow")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "late")
+	assert.Equal(t, http.ErrHandlerTimeout, <-lateWrite)
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	g := New(WithRecover())
+	g.Use(TimeoutWithConfig(TimeoutConfig{Timeout: time.Second, Error: ErrRequestTimeout}))
+	g.Get("/panic", func(c Context) error {
+		panic("boom")
+	})
+	rec := serve(g, http.MethodGet, "/panic")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	// the reported stack is the one of the handler, not of Timeout
+	g.Debug = true
+	rec = serve(g, http.MethodGet, "/panic")
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "boom", body["message"])
+	assert.Contains(t, body["stack"], "TestTimeoutPanic.func1")
+}
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestTimeoutLatePanic(t *testing.T) {
+	logs := new(lockedBuffer)
+	log.SetOutput(logs)
+	defer log.SetOutput(os.Stderr)
+
+	g := New(WithRecover())
+	g.Use(Timeout(20 * time.Millisecond))
+	g.Get("/late", func(c Context) error {
+		<-c.Request().Context().Done()
+		time.Sleep(10 * time.Millisecond)
+		panic("late boom")
+	})
+
+	rec := serve(g, http.MethodGet, "/late")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Eventually(t, func() bool {
+		return strings.Contains(logs.String(), "[PANIC AFTER TIMEOUT] late boom")
+	}, time.Second, 5*time.Millisecond)
+}
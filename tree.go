@@ -1,7 +1,9 @@
 package jago
 
 import (
+	"fmt"
 	"log"
+	"sort"
 	"strings"
 )
 
@@ -9,6 +11,7 @@ type (
 	Trie struct {
 		root           *TreeNode
 		staticChildren map[string]*TreeNode
+		maxParams      int
 	}
 
 	TreeNode struct {
@@ -22,17 +25,30 @@ type (
 		componentList     []string
 		literalsToMatch   []string
 		variablesNames    []string
+		paramNames        []string
+		paramIndexes      []int
 		variableArgsCount int
 		score             int
 		hasWildcard       bool
 		handlers          map[string][]HandlerFunc
+		// groups holds the group of each method registered through one, so
+		// its middlewares are resolved when the route is dispatched.
+		groups map[string]*Group
 	}
 
+	// Mached holds the per-request matching state. It lives on the pooled
+	// context so its slices are reused across requests.
 	Mached struct {
-		results []*TreeNode
+		results    []*TreeNode
+		parts      []string
+		lowerParts []string
 	}
 )
 
+// wildcardParamIndex marks the param holding the path remainder matched by
+// a trailing wildcard.
+const wildcardParamIndex = -1
+
 func newTrie() *Trie {
 	return &Trie{
 		root: &TreeNode{
@@ -44,7 +60,7 @@ func newTrie() *Trie {
 	}
 }
 
-func (t *Trie) add(method, pattern string, handlers ...HandlerFunc) {
+func (t *Trie) add(method, pattern string, g *Group, handlers ...HandlerFunc) {
 	// pattern = strings.ToLower(pattern)
 	if pattern == "/" {
 		pattern = "/*"
@@ -57,21 +73,25 @@ func (t *Trie) add(method, pattern string, handlers ...HandlerFunc) {
 
 	var node *TreeNode
 	if !strings.Contains(pattern, ":") && !strings.Contains(pattern, "*") {
-		node = &TreeNode{
-			hasWildcard: false,
-			segment:     pattern,
-			handlers:    make(map[string][]HandlerFunc),
+		key := strings.ToLower(pattern)
+		if node = t.staticChildren[key]; node == nil {
+			node = &TreeNode{
+				hasWildcard: false,
+				segment:     pattern,
+				handlers:    make(map[string][]HandlerFunc),
+			}
+			t.staticChildren[key] = node
 		}
-		t.staticChildren[strings.ToLower(pattern)] = node
 	} else {
 		node = parsePattern(t.root, segments)
 	}
 	if node != nil {
-		initLeafNode(node, method, pattern, segments, handlers...)
+		initLeafNode(node, method, pattern, segments, g, handlers...)
+		t.maxParams = max(t.maxParams, len(node.paramNames))
 	}
 }
 
-func initLeafNode(node *TreeNode, method, pattern string, segments []string, handlers ...HandlerFunc) {
+func initLeafNode(node *TreeNode, method, pattern string, segments []string, g *Group, handlers ...HandlerFunc) {
 	node.path = pattern
 	node.componentList = segments
 	componentLength := len(node.componentList)
@@ -80,41 +100,77 @@ func initLeafNode(node *TreeNode, method, pattern string, segments []string, han
 	}
 	node.literalsToMatch = make([]string, componentLength)
 	node.variablesNames = make([]string, componentLength)
+	var paramNames []string
+	var paramIndexes []int
+	node.variableArgsCount = 0
 	for i, component := range node.componentList {
 		if strings.Index(component, ":") == 0 {
 			node.variablesNames[i] = component[1:]
+			paramNames = append(paramNames, component[1:])
+			paramIndexes = append(paramIndexes, i)
 			node.variableArgsCount++
 		} else {
 			node.literalsToMatch[i] = strings.ToLower(component)
 		}
 	}
+	if node.hasWildcard {
+		// the wildcard captures the rest of the path, reachable as "*" and,
+		// for "*name" patterns, under its name as well
+		name := segments[componentLength-1][1:]
+		if name != "" {
+			paramNames = append(paramNames, name)
+			paramIndexes = append(paramIndexes, wildcardParamIndex)
+		}
+		paramNames = append(paramNames, "*")
+		paramIndexes = append(paramIndexes, wildcardParamIndex)
+	}
+	// the methods of a node share its params, so they must agree on names
+	if len(node.handlers) > 0 && !equalStrings(node.paramNames, paramNames) {
+		panic(fmt.Sprintf("jago: %s %s conflicts with the param names of %s", method, pattern, node.path))
+	}
+	node.paramNames = paramNames
+	node.paramIndexes = paramIndexes
 
+	// a route is registered once, so a later Add or Group.Use can never
+	// silently swap the chain of an existing route
+	set := func(m string) {
+		if _, ok := node.handlers[m]; ok {
+			panic(fmt.Sprintf("jago: %s %s is already registered", m, pattern))
+		}
+		node.handlers[m] = handlers
+		if g != nil {
+			if node.groups == nil {
+				node.groups = make(map[string]*Group)
+			}
+			node.groups[m] = g
+		}
+	}
 	if method == CONNECT || method == HttpMethodAny {
-		node.handlers[CONNECT] = append(node.handlers[CONNECT], handlers...)
+		set(CONNECT)
 	}
 	if method == DELETE || method == HttpMethodAny {
-		node.handlers[DELETE] = append(node.handlers[DELETE], handlers...)
+		set(DELETE)
 	}
 	if method == GET || method == HttpMethodAny {
-		node.handlers[GET] = append(node.handlers[GET], handlers...)
+		set(GET)
 	}
 	if method == HEAD || method == HttpMethodAny {
-		node.handlers[HEAD] = append(node.handlers[HEAD], handlers...)
+		set(HEAD)
 	}
 	if method == OPTIONS || method == HttpMethodAny {
-		node.handlers[OPTIONS] = append(node.handlers[OPTIONS], handlers...)
+		set(OPTIONS)
 	}
 	if method == PATCH || method == HttpMethodAny {
-		node.handlers[PATCH] = append(node.handlers[PATCH], handlers...)
+		set(PATCH)
 	}
 	if method == POST || method == HttpMethodAny {
-		node.handlers[POST] = append(node.handlers[POST], handlers...)
+		set(POST)
 	}
 	if method == PUT || method == HttpMethodAny {
-		node.handlers[PUT] = append(node.handlers[PUT], handlers...)
+		set(PUT)
 	}
 	if method == TRACE || method == HttpMethodAny {
-		node.handlers[TRACE] = append(node.handlers[TRACE], handlers...)
+		set(TRACE)
 	}
 
 	node.score = 1
@@ -128,6 +184,18 @@ func initLeafNode(node *TreeNode, method, pattern string, segments []string, han
 	}
 }
 
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func parsePattern(parent *TreeNode, segments []string) *TreeNode {
 	segment := strings.ToLower(segments[0])
 	segments = segments[1:]
@@ -143,6 +211,9 @@ func parseSegment(parent *TreeNode, segment string) *TreeNode {
 	if node, ok := parent.segChildren[segment]; ok {
 		return node
 	}
+	if strings.HasPrefix(segment, "*") && parent.wildcardChild != nil {
+		return parent.wildcardChild
+	}
 	if strings.HasPrefix(segment, ":") {
 		if node, ok := parent.paramChildren[segment]; ok {
 			return node
@@ -155,7 +226,7 @@ func parseSegment(parent *TreeNode, segment string) *TreeNode {
 		paramChildren: make(map[string]*TreeNode),
 		handlers:      make(map[string][]HandlerFunc),
 	}
-	if segment == "*" {
+	if strings.HasPrefix(segment, "*") {
 		parent.wildcardChild = node
 		node.hasWildcard = true
 	} else if strings.HasPrefix(segment, ":") {
@@ -166,13 +237,8 @@ func parseSegment(parent *TreeNode, segment string) *TreeNode {
 	return node
 }
 
-func (t *Trie) find(uri, method string) (maxScore int, node *TreeNode) {
-	lowerUri := strings.ToLower(uri)
-	if uri == "/" {
-		uri = "/*"
-	}
-
-	if n, ok := t.staticChildren[lowerUri]; ok {
+func (t *Trie) find(uri, method string, m *Mached) (maxScore int, node *TreeNode) {
+	if n, ok := t.staticChildren[strings.ToLower(uri)]; ok {
 		if _, ok := n.handlers[method]; ok {
 			maxScore = n.score
 			node = n
@@ -180,13 +246,7 @@ func (t *Trie) find(uri, method string) (maxScore int, node *TreeNode) {
 		}
 	}
 
-	pathParts := getURIPaths(uri)
-	matched := &Mached{
-		results: make([]*TreeNode, 0),
-	}
-	matchNode(t.root, method, pathParts, matched)
-
-	for _, n := range matched.results {
+	for _, n := range t.match(uri, m) {
 		// log.Printf("uri: %s, matched node: %s -- score: %d", uri, n.path, n.score)
 		if n.score > maxScore {
 			if _, ok := n.handlers[method]; ok {
@@ -198,8 +258,52 @@ func (t *Trie) find(uri, method string) (maxScore int, node *TreeNode) {
 	return
 }
 
-func matchNode(parent *TreeNode, method string, pathParts []string, m *Mached) {
-	segment := strings.ToLower(pathParts[0])
+// allowedMethods returns the sorted set of methods registered on any node
+// matching uri. An empty result means the path itself is unknown.
+func (t *Trie) allowedMethods(uri string, m *Mached) []string {
+	seen := make(map[string]bool)
+	if n, ok := t.staticChildren[strings.ToLower(uri)]; ok {
+		for m := range n.handlers {
+			seen[m] = true
+		}
+	}
+	for _, n := range t.match(uri, m) {
+		for m, handlers := range n.handlers {
+			if len(handlers) > 0 {
+				seen[m] = true
+			}
+		}
+	}
+
+	methods := make([]string, 0, len(seen))
+	for m := range seen {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+func (t *Trie) match(uri string, m *Mached) []*TreeNode {
+	m.reset(uri)
+	if len(m.lowerParts) == 0 {
+		if t.root.wildcardChild != nil {
+			m.results = append(m.results, t.root.wildcardChild)
+		}
+		return m.results
+	}
+	matchNode(t.root, m.lowerParts, m)
+	return m.results
+}
+
+func (m *Mached) reset(uri string) {
+	m.results = m.results[:0]
+	m.parts = appendURIPaths(m.parts[:0], uri)
+	m.lowerParts = appendURIPaths(m.lowerParts[:0], strings.ToLower(uri))
+}
+
+// matchNode collects the leaf nodes matching the lower-cased path segments.
+func matchNode(parent *TreeNode, pathParts []string, m *Mached) {
+	segment := pathParts[0]
 	segments := pathParts[1:]
 
 	if len(segments) == 0 {
@@ -221,10 +325,10 @@ func matchNode(parent *TreeNode, method string, pathParts []string, m *Mached) {
 
 	} else {
 		if n, ok := parent.segChildren[segment]; ok {
-			matchNode(n, method, segments, m)
+			matchNode(n, segments, m)
 		}
 		for _, n := range parent.paramChildren {
-			matchNode(n, method, segments, m)
+			matchNode(n, segments, m)
 		}
 		if parent.wildcardChild != nil {
 			m.results = append(m.results, parent.wildcardChild)
@@ -261,13 +365,3 @@ func printNode(node *TreeNode, prefix string) {
 		log.Printf("%s%s [%d] -- %v", prefix, node.wildcardChild.segment, node.wildcardChild.score, node.wildcardChild.leaf)
 	}
 }
-
-func (n *TreeNode) getPathParam(pathParts []string) map[string]string {
-	pathParam := make(map[string]string)
-	for i, pname := range n.variablesNames {
-		if pname != "" {
-			pathParam[pname] = pathParts[i]
-		}
-	}
-	return pathParam
-}
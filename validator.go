@@ -0,0 +1,301 @@
+package jago
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type (
+	// Validator checks a bound value. Returning FieldErrors lets Validate
+	// report every failing field in the 422 response.
+	Validator interface {
+		Validate(i interface{}) error
+	}
+
+	// TagValidator is the built-in Validator driven by struct tags such as
+	// `validate:"required,min=3,max=20"`. Supported rules are required,
+	// omitempty, min, max, len, oneof, email and regexp; regexp must be the
+	// last rule since its pattern may contain commas. Every rule applies to
+	// zero values too, unless omitempty is given. Invalid tags make Validate
+	// return a 500 HTTPError.
+	TagValidator struct {
+		TagName string
+		types   sync.Map
+	}
+
+	fieldRules struct {
+		index     int
+		name      string
+		required  bool
+		omitempty bool
+		rules     []fieldRule
+	}
+
+	fieldRule struct {
+		name  string
+		param string
+		limit float64
+		re    *regexp.Regexp
+	}
+)
+
+const defaultValidateTag = "validate"
+
+func NewTagValidator() *TagValidator {
+	return &TagValidator{TagName: defaultValidateTag}
+}
+
+// Validate runs Jago.Validator against i and turns failures into a 422
+// HTTPError.
+func (c *context) Validate(i interface{}) error {
+	if c.j.Validator == nil {
+		return ErrValidatorNotRegistered
+	}
+	switch err := c.j.Validator.Validate(i).(type) {
+	case nil:
+		return nil
+	case *HTTPError:
+		return err
+	case FieldErrors:
+		return NewHTTPError(http.StatusUnprocessableEntity, err)
+	default:
+		return NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+}
+
+func (v *TagValidator) Validate(i interface{}) error {
+	val := reflect.ValueOf(i)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+	errs, err := v.validateStruct(val, "")
+	if err != nil {
+		return &HTTPError{Code: http.StatusInternalServerError, Message: http.StatusText(http.StatusInternalServerError), Internal: err}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (v *TagValidator) validateStruct(val reflect.Value, prefix string) (errs FieldErrors, err error) {
+	fields, err := v.structRules(val.Type())
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		name := prefix + f.name
+		field := val.Field(f.index)
+		if msg := f.check(field); msg != "" {
+			errs = append(errs, &FieldError{Field: name, Message: msg})
+			continue
+		}
+
+		for field.Kind() == reflect.Ptr && !field.IsNil() {
+			field = field.Elem()
+		}
+		if field.Kind() == reflect.Struct && field.Type() != timeType {
+			nested, err := v.validateStruct(field, name+".")
+			if err != nil {
+				return nil, err
+			}
+			errs = append(errs, nested...)
+		}
+	}
+	return
+}
+
+// structRules parses the tags of t once and caches the result, so invalid
+// tags are reported on first use instead of while checking values.
+func (v *TagValidator) structRules(t reflect.Type) ([]fieldRules, error) {
+	if cached, ok := v.types.Load(t); ok {
+		return cached.([]fieldRules), nil
+	}
+	var fields []fieldRules
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		f := fieldRules{index: i, name: validateFieldName(sf)}
+		if tag := sf.Tag.Get(v.TagName); tag != "" && tag != "-" {
+			if err := f.parse(sf.Type, tag); err != nil {
+				return nil, fmt.Errorf("jago: invalid %s tag on %s.%s: %w", v.TagName, t, sf.Name, err)
+			}
+		}
+		fields = append(fields, f)
+	}
+	v.types.Store(t, fields)
+	return fields, nil
+}
+
+func (f *fieldRules) parse(typ reflect.Type, tag string) error {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	for tag != "" {
+		var rule string
+		if strings.HasPrefix(tag, "regexp=") {
+			rule, tag = tag, ""
+		} else if i := strings.IndexByte(tag, ','); i >= 0 {
+			rule, tag = tag[:i], tag[i+1:]
+		} else {
+			rule, tag = tag, ""
+		}
+		r := fieldRule{name: rule}
+		if i := strings.IndexByte(rule, '='); i >= 0 {
+			r.name, r.param = rule[:i], rule[i+1:]
+		}
+
+		switch r.name {
+		case "omitempty":
+			f.omitempty = true
+			continue
+		case "required":
+			f.required = true
+			continue
+		case "min", "max", "len":
+			limit, err := strconv.ParseFloat(r.param, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s parameter %q", r.name, r.param)
+			}
+			if !measurable(typ) {
+				return fmt.Errorf("%s cannot be applied to %s", r.name, typ)
+			}
+			r.limit = limit
+		case "oneof", "email":
+		case "regexp":
+			re, err := regexp.Compile(r.param)
+			if err != nil {
+				return err
+			}
+			r.re = re
+		default:
+			return fmt.Errorf("unknown rule %q", r.name)
+		}
+		f.rules = append(f.rules, r)
+	}
+	return nil
+}
+
+// check returns the message of the first failing rule. Nil pointers are
+// checked as the zero value of their element type.
+func (f *fieldRules) check(field reflect.Value) string {
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field = reflect.Zero(field.Type().Elem())
+			continue
+		}
+		field = field.Elem()
+	}
+	zero := field.IsZero() || (hasLength(field) && field.Len() == 0)
+	if zero {
+		if f.required {
+			return "is required"
+		}
+		if f.omitempty {
+			return ""
+		}
+	}
+	for _, r := range f.rules {
+		if msg := r.check(field); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+func (r *fieldRule) check(field reflect.Value) string {
+	switch r.name {
+	case "min", "max", "len":
+		n, isLen := measure(field)
+		what := "must be"
+		if isLen {
+			what = "length must be"
+		}
+		switch {
+		case r.name == "min" && n < r.limit:
+			return fmt.Sprintf("%s at least %s", what, r.param)
+		case r.name == "max" && n > r.limit:
+			return fmt.Sprintf("%s at most %s", what, r.param)
+		case r.name == "len" && n != r.limit:
+			return fmt.Sprintf("%s %s", what, r.param)
+		}
+	case "oneof":
+		s := fmt.Sprint(field.Interface())
+		for _, option := range strings.Fields(r.param) {
+			if s == option {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of [%s]", r.param)
+	case "email":
+		s := fmt.Sprint(field.Interface())
+		if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+			return "must be a valid email address"
+		}
+	case "regexp":
+		if !r.re.MatchString(fmt.Sprint(field.Interface())) {
+			return fmt.Sprintf("must match %s", r.param)
+		}
+	}
+	return ""
+}
+
+// measure returns the value compared by min, max and len: the length of
+// strings and collections, the value itself for numbers.
+func measure(field reflect.Value) (n float64, isLen bool) {
+	switch field.Kind() {
+	case reflect.String:
+		return float64(len([]rune(field.String()))), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(field.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint()), false
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), false
+	}
+	return 0, false
+}
+
+func measurable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func hasLength(field reflect.Value) bool {
+	switch field.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return true
+	}
+	return false
+}
+
+func validateFieldName(sf reflect.StructField) string {
+	if tag := sf.Tag.Get("json"); tag != "" {
+		if name := strings.Split(tag, ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
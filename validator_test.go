@@ -0,0 +1,102 @@
+package jago
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type validateAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type validateUser struct {
+	Name    string           `json:"name" validate:"required,min=3,max=8"`
+	Code    string           `json:"code" validate:"len=4,regexp=^[A-Z]{2,4}$"`
+	Age     int              `json:"age" validate:"min=18"`
+	Role    string           `json:"role" validate:"omitempty,oneof=admin user"`
+	Email   string           `json:"email" validate:"omitempty,email"`
+	Tags    []string         `json:"tags" validate:"omitempty,max=2"`
+	Address *validateAddress `json:"address"`
+}
+
+func TestTagValidator(t *testing.T) {
+	v := NewTagValidator()
+	assert.NoError(t, v.Validate(&validateUser{Name: "jago", Code: "ABCD", Age: 20, Role: "admin", Email: "a@b.io"}))
+	assert.NoError(t, v.Validate(&validateUser{Name: "jago", Code: "ABCD", Age: 18}))
+
+	// zero values are checked unless the field is omitempty
+	assert.Equal(t, FieldErrors{
+		{Field: "code", Message: "length must be 4"},
+		{Field: "age", Message: "must be at least 18"},
+	}, v.Validate(&validateUser{Name: "jago"}))
+
+	err := v.Validate(&validateUser{
+		Code:    "AB1C",
+		Age:     3,
+		Role:    "root",
+		Email:   "nope",
+		Tags:    []string{"a", "b", "c"},
+		Address: &validateAddress{},
+	})
+	errs, ok := err.(FieldErrors)
+	assert.True(t, ok)
+	assert.Equal(t, FieldErrors{
+		{Field: "name", Message: "is required"},
+		{Field: "code", Message: "must match ^[A-Z]{2,4}$"},
+		{Field: "age", Message: "must be at least 18"},
+		{Field: "role", Message: "must be one of [admin user]"},
+		{Field: "email", Message: "must be a valid email address"},
+		{Field: "tags", Message: "length must be at most 2"},
+		{Field: "address.city", Message: "is required"},
+	}, errs)
+}
+
+func TestContextValidate(t *testing.T) {
+	g := New()
+	c := bindContext(g, http.MethodPost, "/users", "/users", MIMEApplicationJSON, `{"name":"jo"}`)
+	assert.Equal(t, ErrValidatorNotRegistered, c.Validate(&validateUser{}))
+
+	g = New()
+	g.Validator = NewTagValidator()
+	g.AutoValidate = true
+	c = bindContext(g, http.MethodPost, "/users", "/users", MIMEApplicationJSON, `{"name":"jo"}`)
+	he, ok := c.Bind(&validateUser{}).(*HTTPError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusUnprocessableEntity, he.Code)
+	assert.Equal(t, FieldErrors{
+		{Field: "name", Message: "length must be at least 3"},
+		{Field: "code", Message: "length must be 4"},
+		{Field: "age", Message: "must be at least 18"},
+	}, he.Message)
+}
+
+func TestTagValidatorInvalidTags(t *testing.T) {
+	v := NewTagValidator()
+	for _, i := range []interface{}{
+		&struct {
+			A string `validate:"nope"`
+		}{},
+		&struct {
+			A int `validate:"min=abc"`
+		}{},
+		&struct {
+			A string `validate:"regexp=("`
+		}{},
+		&struct {
+			A bool `validate:"max=1"`
+		}{},
+	} {
+		he, ok := v.Validate(i).(*HTTPError)
+		if assert.True(t, ok) {
+			assert.Equal(t, http.StatusInternalServerError, he.Code)
+			assert.Error(t, he.Internal)
+		}
+	}
+
+	var n *int
+	assert.Equal(t, FieldErrors{{Field: "N", Message: "must be at least 1"}}, v.Validate(&struct {
+		N *int `validate:"min=1"`
+	}{N: n}))
+}